@@ -0,0 +1,62 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/betterde/gonew/internal/registry"
+)
+
+// noAnalytics, set by --no-analytics, keeps a generation out of the index
+// even when analytics are enabled in the configuration file.
+var noAnalytics bool
+
+func init() {
+	initCmd.Flags().BoolVar(&noAnalytics, "no-analytics", false, "Do not count this generation in the template index, even with analytics enabled in the configuration file")
+}
+
+// recordGeneration counts the generation of the project from components in
+// the template index when the user opted in to analytics. Nothing but the
+// templates and their versions is recorded.
+func recordGeneration(components []*component) error {
+	filename := indexFile(initIndex)
+	if !userSettings.Analytics || noAnalytics || filename == "" {
+		return nil
+	}
+	// Analytics go to an existing index file only, never create one, and
+	// indexes served over HTTP cannot be written.
+	if registry.IsURL(filename) {
+		return nil
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return registry.Update(filename, func(index *registry.Index) error {
+		for _, c := range components {
+			index.RecordGeneration(c.mod, c.info.Version, now)
+		}
+		return nil
+	})
+}
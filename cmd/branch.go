@@ -0,0 +1,201 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/git"
+	"github.com/betterde/gonew/internal/safepath"
+)
+
+// scaffoldBranch is the new branch the generated files are committed to.
+var scaffoldBranch string
+
+func init() {
+	initCmd.Flags().StringVar(&scaffoldBranch, "branch", "", "Commit the generated files to this new branch of the git repository containing the target directory, leaving the working tree and current branch untouched")
+}
+
+// branchTarget generates a project into a scratch copy of the target
+// directory as committed at HEAD, and commits it to a new branch with an
+// index of its own, so neither the working tree nor the index of the
+// repository change.
+type branchTarget struct {
+	repo    string // root of the repository
+	rel     string // target directory relative to repo
+	scratch string // scratch work tree, holding rel
+	index   string // scratch index file
+}
+
+// newBranchTarget prepares generating into dir, inside a git repository, on
+// the new branch scaffoldBranch.
+func newBranchTarget(ctx context.Context, dir string) (*branchTarget, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// The target directory usually does not exist yet: resolve its
+	// closest existing ancestor to find the repository.
+	existing, rest := abs, ""
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return nil, fmt.Errorf("%s does not exist", dir)
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	resolved, err := safepath.Resolve(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.Run(ctx, resolved, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("--branch needs the target directory inside a git repository: %v", err)
+	}
+	repo, err = safepath.Resolve(repo)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(repo, filepath.Join(resolved, rest))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := git.Run(ctx, repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+scaffoldBranch); err == nil {
+		return nil, fmt.Errorf("branch %s already exists", scaffoldBranch)
+	}
+	if _, err := git.Run(ctx, repo, "check-ref-format", "--branch", scaffoldBranch); err != nil {
+		return nil, fmt.Errorf("invalid branch name %s", scaffoldBranch)
+	}
+
+	scratch, err := os.MkdirTemp("", "gonew-branch-")
+	if err != nil {
+		return nil, err
+	}
+	b := &branchTarget{repo: repo, rel: rel, scratch: scratch, index: filepath.Join(scratch, ".git-index")}
+	if err := b.checkout(ctx); err != nil {
+		b.cleanup()
+		return nil, err
+	}
+	return b, nil
+}
+
+// dir is where the project is generated.
+func (b *branchTarget) dir() string {
+	return filepath.Join(b.scratch, "tree", b.rel)
+}
+
+// checkout copies the target directory as committed at HEAD into the scratch
+// work tree, so existing files are found as they are on the new branch.
+func (b *branchTarget) checkout(ctx context.Context) error {
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "git", "archive", "--format=tar", "HEAD", "--", filepath.ToSlash(b.rel))
+	command.Dir = b.repo
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		// The target directory is not in HEAD: nothing to copy.
+		if _, lsErr := git.Run(ctx, b.repo, "rev-parse", "--verify", "--quiet", "HEAD:"+filepath.ToSlash(b.rel)); lsErr != nil {
+			return nil
+		}
+		return fmt.Errorf("git archive: %v\n%s", err, stderr.Bytes())
+	}
+
+	tree := filepath.Join(b.scratch, "tree")
+	archive := tar.NewReader(&stdout)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := safepath.CheckRel(filepath.FromSlash(header.Name)); err != nil {
+			return err
+		}
+		target := filepath.Join(tree, filepath.FromSlash(header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0777)
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0777); err == nil {
+				var data []byte
+				if data, err = io.ReadAll(archive); err == nil {
+					err = os.WriteFile(target, data, os.FileMode(header.Mode)&0777)
+				}
+			}
+		case tar.TypeSymlink:
+			if err = os.MkdirAll(filepath.Dir(target), 0777); err == nil {
+				err = os.Symlink(header.Linkname, target)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// commit commits the generated project on top of HEAD to the new branch and
+// returns the commit.
+func (b *branchTarget) commit(ctx context.Context, message string) (string, error) {
+	env := []string{"GIT_INDEX_FILE=" + b.index}
+	tree := filepath.Join(b.scratch, "tree")
+	steps := [][]string{
+		{"read-tree", "HEAD"},
+		{"--work-tree", tree, "add", "--all", "--", filepath.ToSlash(b.rel)},
+	}
+	for _, args := range steps {
+		if _, err := git.RunEnv(ctx, b.repo, env, args...); err != nil {
+			return "", err
+		}
+	}
+	id, err := git.RunEnv(ctx, b.repo, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	commit, err := git.Run(ctx, b.repo, "commit-tree", id, "-p", "HEAD", "-m", message)
+	if err != nil {
+		return "", err
+	}
+	if _, err := git.Run(ctx, b.repo, "branch", scaffoldBranch, commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// cleanup removes the scratch work tree.
+func (b *branchTarget) cleanup() {
+	os.RemoveAll(b.scratch)
+}
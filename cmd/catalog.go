@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/catalog"
+	"github.com/betterde/gonew/internal/sandbox"
+)
+
+// registerCatalog registers the generated service in the catalog declared by
+// the template, instead of only generating its descriptor.
+var registerCatalog bool
+
+func init() {
+	initCmd.Flags().BoolVar(&registerCatalog, "register-catalog", false, "Register the generated service in the service catalog declared by the template")
+}
+
+// catalogEntry is a rendered catalog section.
+type catalogEntry struct {
+	component    *component
+	provider     string
+	registration catalog.Registration
+}
+
+// writeCatalogInfo renders the catalog section of the first component
+// declaring one, and generates catalog-info.yaml from it unless the template
+// generates its own.
+func writeCatalogInfo(box *sandbox.Sandbox, components []*component, inputs map[string]string, features map[string]bool, written map[string]bool) (*catalogEntry, error) {
+	for _, c := range components {
+		section := c.config.Catalog
+		if section == nil {
+			continue
+		}
+		data := c.context(inputs, features)
+		render := func(name, text, fallback string) (string, error) {
+			if text == "" {
+				return fallback, nil
+			}
+			out, err := c.engine.Render("catalog "+name, text, data)
+			if err != nil {
+				return "", &templateError{component: c, file: "catalog " + name, err: err}
+			}
+			return strings.TrimSpace(string(out)), nil
+		}
+
+		entry := &catalogEntry{component: c, provider: section.Provider}
+		fields := []struct {
+			name, text, fallback string
+			value                *string
+		}{
+			{"name", section.Entity.Name, path.Base(dstMod), &entry.registration.Entity.Name},
+			{"description", section.Entity.Description, "", &entry.registration.Entity.Description},
+			{"owner", section.Entity.Owner, "", &entry.registration.Entity.Owner},
+			{"type", section.Entity.Type, "service", &entry.registration.Entity.Type},
+			{"lifecycle", section.Entity.Lifecycle, "experimental", &entry.registration.Entity.Lifecycle},
+			{"system", section.Entity.System, "", &entry.registration.Entity.System},
+			{"url", section.URL, "", &entry.registration.URL},
+			{"target", section.Target, "", &entry.registration.Target},
+		}
+		for _, field := range fields {
+			value, err := render(field.name, field.text, field.fallback)
+			if err != nil {
+				return nil, err
+			}
+			*field.value = value
+		}
+		if section.TokenEnv != "" {
+			entry.registration.Token = os.Getenv(section.TokenEnv)
+		}
+
+		if written[catalog.FileName] || !onlySelected(catalog.FileName) {
+			return entry, nil
+		}
+		out, err := entry.registration.Entity.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if err := box.WriteFile(catalog.FileName, out, 0666); err != nil {
+			return nil, err
+		}
+		written[catalog.FileName] = true
+		return entry, nil
+	}
+	return nil, nil
+}
+
+// register registers the entry with its provider, adding the link to the
+// registered service to s.
+func (entry *catalogEntry) register(ctx context.Context, s *summary) error {
+	provider, err := catalog.Lookup(entry.provider)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	link, err := provider.Register(ctx, client, entry.registration)
+	if err != nil {
+		return fmt.Errorf("registering %s in the catalog: %v", entry.registration.Entity.Name, err)
+	}
+	if link != "" {
+		s.addArtifact("Catalog", link)
+	}
+	return nil
+}
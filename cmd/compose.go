@@ -0,0 +1,582 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/betterde/gonew/internal/editorconfig"
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/i18n"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/internal/safepath"
+	"github.com/betterde/gonew/internal/sandbox"
+	"golang.org/x/mod/modfile"
+)
+
+// component is a single template applied by init. Several components joined
+// with "+" on the command line are applied in order onto one destination.
+type component struct {
+	mod          string
+	query        string
+	source       resolver
+	info         *moduleInfo
+	config       *project.Config
+	engine       render.Engine
+	root         string
+	rootMod      string
+	editorConfig *editorconfig.Config
+	catalogs     *i18n.Catalogs
+	// helper holds the values the template's helper program added to the
+	// context, if it has one.
+	helper map[string]any
+}
+
+func (c *component) String() string {
+	return c.mod + "@" + c.query
+}
+
+// renderOptions returns the options of the engine rendering the files of
+// the template config.
+func renderOptions(config *project.Config) render.Options {
+	left, right := config.Delims()
+	return render.Options{
+		TrimBlocks:   config.Whitespace.TrimBlocks,
+		LStripBlocks: config.Whitespace.LStripBlocks,
+		LeftDelim:    left,
+		RightDelim:   right,
+	}
+}
+
+// context returns the data the component's files are rendered with: the
+// context of its manifest and the values of its helper program.
+func (c *component) context(inputs map[string]string, features map[string]bool) map[string]any {
+	data := c.config.Context(inputs, features)
+	for key, value := range c.helper {
+		data[key] = value
+	}
+	return data
+}
+
+// load reads the manifest of the component from its downloaded module and
+// prepares its engine, with funcs added to the template functions. It
+// returns the manifest in template.yaml form.
+func (c *component) load(funcs map[string]any) ([]byte, error) {
+	var manifest []byte
+	var err error
+	c.config, manifest, err = project.Find(c.info.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(delimiters) > 0 {
+		c.config.Delimiters = delimiters
+	}
+	if err := c.config.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.root, err = templateRoot(c.info.Dir, c.config)
+	if err != nil {
+		return nil, err
+	}
+	c.rootMod, err = rootModule(c)
+	if err != nil {
+		return nil, err
+	}
+	all := render.Funcs(c.root)
+	for name, fn := range funcs {
+		all[name] = fn
+	}
+	if c.config.I18n.Catalogs != "" {
+		c.catalogs, err = i18n.Load(filepath.Join(c.root, filepath.FromSlash(c.config.I18n.Catalogs)), c.config.I18n.Fallback)
+		if err != nil {
+			return nil, err
+		}
+		all["t"] = c.catalogs.T
+	}
+	opts := renderOptions(c.config)
+	opts.Root = c.root
+	c.engine, err = render.New(c.config.Engine, all, opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.Normalize.EditorConfig {
+		data, err := os.ReadFile(filepath.Join(c.root, editorconfig.FileName))
+		if err != nil {
+			return nil, err
+		}
+		c.editorConfig = editorconfig.Parse(data)
+	}
+	return manifest, nil
+}
+
+// useLocale selects the message catalog of the locale answered for the
+// component's locale variable.
+func (c *component) useLocale(inputs map[string]string) error {
+	if c.catalogs == nil {
+		return nil
+	}
+	name := c.config.LocaleVariable()
+	if name == "" {
+		return fmt.Errorf("%s: i18n needs a variable of type locale", c)
+	}
+	if err := c.catalogs.Use(inputs[name]); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// importVariables adds the variables of the modules the component's
+// manifest imports, in order.
+func (c *component) importVariables(ctx context.Context) error {
+	for _, source := range c.config.Imports {
+		if !strings.Contains(source, "@") {
+			source += "@latest"
+		}
+		info, err := downloadModule(ctx, source)
+		if err != nil {
+			return err
+		}
+		imported, _, err := project.Find(info.Dir)
+		if err != nil {
+			return fmt.Errorf("imports %s: %v", source, err)
+		}
+		if err := imported.Validate(); err != nil {
+			return fmt.Errorf("imports %s: %v", source, err)
+		}
+		c.config.Import(imported)
+	}
+	return nil
+}
+
+// parseSources splits the init source argument, such as
+// "example.com/base+example.com/grpc@v1.2.0", into its components. A source
+// may also be a bundle file written by template bundle, a local template
+// directory such as ./my-template, or a git URL with an optional ref such
+// as https://github.com/org/tpl.git@feature.
+func parseSources(ctx context.Context, arg string) ([]*component, error) {
+	var sources []string
+	for _, source := range strings.Split(arg, "+") {
+		// Keep "+incompatible" and other build metadata with its version.
+		if n := len(sources); n > 0 && strings.Contains(sources[n-1], "@") && !strings.Contains(source, "/") {
+			sources[n-1] += "+" + source
+			continue
+		}
+		sources = append(sources, source)
+	}
+
+	components := make([]*component, 0, len(sources))
+	for _, source := range sources {
+		c, err := parseSource(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// mergeConfigs combines the manifests of all components into the one used
+// for prompting. Variables, features and layouts declared by several
+// components are offered once, using the first declaration.
+func mergeConfigs(configs []*project.Config) *project.Config {
+	merged := *configs[0]
+	merged.Variables = nil
+	merged.Features = nil
+	merged.Layouts = nil
+	merged.Formatters = nil
+	merged.VariableMigrations = nil
+	merged.Constraints = nil
+
+	seen := make(map[string]bool)
+	features := make(map[string]bool)
+	layouts := make(map[string]bool)
+	for _, config := range configs {
+		for _, variable := range config.Variables {
+			if seen[variable.Name] {
+				continue
+			}
+			seen[variable.Name] = true
+			merged.Variables = append(merged.Variables, variable)
+		}
+		for _, feature := range config.Features {
+			if features[feature.Name] {
+				continue
+			}
+			features[feature.Name] = true
+			merged.Features = append(merged.Features, feature)
+		}
+		merged.Formatters = append(merged.Formatters, config.Formatters...)
+		merged.VariableMigrations = append(merged.VariableMigrations, config.VariableMigrations...)
+		merged.Constraints = append(merged.Constraints, config.Constraints...)
+		for _, layout := range config.Layouts {
+			if layouts[layout.Name] {
+				continue
+			}
+			layouts[layout.Name] = true
+			merged.Layouts = append(merged.Layouts, layout)
+		}
+	}
+	return &merged
+}
+
+// plannedFile is a template file and the path it is generated at.
+type plannedFile struct {
+	component *component
+	layout    *project.Layout
+	src       string
+	rel       string
+	dstRel    string
+	// executable is set for scripts matching the manifest's executables
+	// globs or starting with the exec marker.
+	executable bool
+	// item is the matrix element the file is generated for, if any.
+	item *matrixItem
+}
+
+// sanitizePathValues returns a copy of v, the data file and directory names
+// are rendered with, whose strings are made safe as parts of a path element
+// by safepath.Segment.
+func sanitizePathValues(v any) any {
+	if v == nil {
+		return nil
+	}
+	return sanitizeValue(reflect.ValueOf(v)).Interface()
+}
+
+func sanitizeValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(safepath.Segment(v.String()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(sanitizeValue(v.Elem()))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(sanitizeValue(v.Elem()))
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), sanitizeValue(iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(sanitizeValue(v.Index(i)))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(sanitizeValue(v.Field(i)))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// matrixItem is an element of the list variable of a generate.matrix entry,
+// available to the files generated for it as .Item and .Index.
+type matrixItem struct {
+	value any
+	index int
+}
+
+// context returns data with the matrix element added. A nil item leaves
+// data as is.
+func (item *matrixItem) context(data map[string]any) map[string]any {
+	if item == nil {
+		return data
+	}
+	scoped := make(map[string]any, len(data)+2)
+	for k, v := range data {
+		scoped[k] = v
+	}
+	scoped["Item"] = item.value
+	scoped["Index"] = item.index
+	return scoped
+}
+
+// matrixItems returns the elements of the list variable of m in data, the
+// rendering context, or of the models for the variable Models. An
+// unanswered variable has no elements.
+func matrixItems(m *project.Matrix, data map[string]any) ([]*matrixItem, error) {
+	list := data[m.Variable]
+	if list == nil {
+		return nil, nil
+	}
+	values := reflect.ValueOf(list)
+	if values.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("generate.matrix: variable %s must be of type list", m.Variable)
+	}
+	items := make([]*matrixItem, values.Len())
+	for i := range items {
+		items[i] = &matrixItem{value: values.Index(i).Interface(), index: i}
+	}
+	return items, nil
+}
+
+// mergeable lists the files several components may provide; they are merged
+// into the first component's copy rather than reported as conflicts.
+var mergeable = map[string]bool{
+	"go.mod":         true,
+	"go.sum":         true,
+	project.FileName: true,
+}
+
+// dstPath returns the path in the project of the template file at rel, both
+// relative, with the names config restores and the directories of layout.
+func dstPath(config *project.Config, layout *project.Layout, rel string) string {
+	return filepath.FromSlash(layout.Map(config.Restored(filepath.ToSlash(rel))))
+}
+
+// planFiles lists the files every component generates given the answers,
+// and fails if two components would generate the same file. Files of
+// features that are not selected, or whose files condition does not hold,
+// are left out, and the others are placed
+// according to the selected layout of each component.
+func planFiles(ctx context.Context, components []*component, inputs map[string]string, features map[string]bool, layoutName string) ([]*plannedFile, error) {
+	var files []*plannedFile
+	owners := make(map[string]*component)
+	for _, c := range components {
+		data := c.context(inputs, features)
+		layout := c.config.Layout(layoutName)
+		err := filepath.WalkDir(c.root, func(src string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(c.root, src)
+			if err != nil {
+				return err
+			}
+			// Directories are created as the files inside them are written,
+			// so those only holding excluded files are never created, and
+			// those whose files condition does not hold are not even read.
+			if d.IsDir() {
+				if rel == "." {
+					return nil
+				}
+				skipped, err := c.config.Skipped(filepath.ToSlash(rel), inputs, features)
+				if err != nil {
+					return fmt.Errorf("%s: %v", c, err)
+				}
+				if skipped {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if err := safepath.CheckRel(rel); err != nil {
+				return fmt.Errorf("refusing to write template file: %v", err)
+			}
+
+			if ignoredFile(c.config, filepath.ToSlash(rel)) || c.config.Excluded(filepath.ToSlash(rel), features) {
+				return nil
+			}
+			if skipped, err := c.config.Skipped(filepath.ToSlash(rel), inputs, features); err != nil {
+				return fmt.Errorf("%s: %v", c, err)
+			} else if skipped {
+				return nil
+			}
+			// Symlinks could copy files of the host, such as SSH keys, into
+			// the project; generateFile checks again before reading.
+			if !d.Type().IsRegular() {
+				return fmt.Errorf("refusing to read template file %s: not a regular file", filepath.ToSlash(rel))
+			}
+
+			items := []*matrixItem{nil}
+			if m := c.config.Matrix(filepath.ToSlash(rel)); m != nil {
+				if items, err = matrixItems(m, data); err != nil {
+					return fmt.Errorf("%s: %v", c, err)
+				}
+			}
+			for _, item := range items {
+				// File and directory names may be templated too, check the
+				// result again since answers could introduce separators.
+				dstRel := rel
+				if left, _ := c.config.Delims(); strings.Contains(rel, left) {
+					pathData := item.context(data)
+					if !c.config.RawPaths {
+						pathData = sanitizePathValues(pathData).(map[string]any)
+					}
+					name, err := c.engine.Render(rel, rel, pathData)
+					if err != nil {
+						return &templateError{component: c, file: filepath.ToSlash(rel), err: err}
+					}
+					dstRel = string(name)
+					if err := safepath.CheckRel(dstRel); err != nil {
+						return fmt.Errorf("refusing to write template file: %v", err)
+					}
+				}
+				dstRel = dstPath(c.config, layout, dstRel)
+
+				if owner, ok := owners[dstRel]; ok && !mergeable[dstRel] {
+					if owner == c && item != nil {
+						return fmt.Errorf("%s: %s is generated more than once, its name must use .Item or .Index to tell the elements of the matrix apart", c, dstRel)
+					}
+					return fmt.Errorf("%s is generated by both %s and %s", dstRel, owner, c)
+				}
+				owners[dstRel] = c
+
+				files = append(files, &plannedFile{
+					component:  c,
+					layout:     layout,
+					src:        src,
+					rel:        rel,
+					dstRel:     dstRel,
+					executable: glob.MatchAny(c.config.Executables, filepath.ToSlash(dstRel)),
+					item:       item,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range inheritedFiles(c.config, c.info.Dir, c.root) {
+			src := filepath.Join(c.info.Dir, name)
+			files = append(files, &plannedFile{component: c, layout: layout, src: src, rel: name, dstRel: name})
+		}
+	}
+	return files, nil
+}
+
+// ignoredFile reports whether the template file at rel, a slash-separated
+// path relative to the root of config, is never generated: it is ignored or
+// holds the instructions for users of the template.
+func ignoredFile(config *project.Config, rel string) bool {
+	return config.Ignored(rel) || rel == project.ReadmeFileName
+}
+
+// inheritedFiles returns the names of the files of the template module in
+// dir that its root directory is generated with: a root without go.mod and
+// go.sum of its own gets those of the module, which hold its requirements.
+func inheritedFiles(config *project.Config, dir, root string) []string {
+	if config.Root == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range []string{"go.mod", "go.sum"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// mergeFile combines data, a later component's version of a mergeable file,
+// with the one already written to the sandbox.
+func mergeFile(box *sandbox.Sandbox, name string, data []byte) ([]byte, error) {
+	existing, err := box.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "go.mod":
+		return mergeGoMod(existing, data)
+	case "go.sum":
+		return mergeLines(existing, data), nil
+	default:
+		// Only the first component's manifest is kept.
+		return existing, nil
+	}
+}
+
+// mergeGoMod adds the requirements of add that base lacks.
+func mergeGoMod(base, add []byte) ([]byte, error) {
+	baseFile, err := modfile.ParseLax("go.mod", base, nil)
+	if err != nil {
+		return nil, err
+	}
+	addFile, err := modfile.ParseLax("go.mod", add, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	required := make(map[string]bool, len(baseFile.Require))
+	for _, r := range baseFile.Require {
+		required[r.Mod.Path] = true
+	}
+	for _, r := range addFile.Require {
+		if required[r.Mod.Path] {
+			continue
+		}
+		if err := baseFile.AddRequire(r.Mod.Path, r.Mod.Version); err != nil {
+			return nil, err
+		}
+	}
+	baseFile.Cleanup()
+	return baseFile.Format()
+}
+
+// mergeLines appends the lines of add missing from base.
+func mergeLines(base, add []byte) []byte {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(base), "\n") {
+		seen[line] = true
+	}
+
+	merged := strings.TrimRight(string(base), "\n") + "\n"
+	for _, line := range strings.Split(string(add), "\n") {
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		merged += line + "\n"
+	}
+	return []byte(merged)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/render"
+)
+
+// testComponent returns a component for a template made of files, given as
+// slash-separated paths and contents.
+func testComponent(t testing.TB, files map[string]string) *component {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine, err := render.New("", render.Funcs(root), render.Options{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &component{
+		mod:    "example.com/template",
+		query:  "v1.0.0",
+		config: &project.Config{RawPaths: true},
+		engine: engine,
+		info:   &moduleInfo{Dir: root},
+		root:   root,
+	}
+}
+
+func TestPlanFilesRefusesSymlinks(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "id_rsa")
+	if err := os.WriteFile(secret, []byte("PRIVATE KEY"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := testComponent(t, map[string]string{"main.go": "package main\n"})
+	if err := os.Symlink(secret, filepath.Join(c.root, "id_rsa")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := planFiles(context.Background(), []*component{c}, nil, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Fatalf("planFiles with a symlink = %v, want a not a regular file error", err)
+	}
+}
+
+func TestPlanFilesRefusesEscapingNames(t *testing.T) {
+	for _, name := range []string{"../escaped", "a/../../escaped", "/etc/escaped"} {
+		c := testComponent(t, map[string]string{"{{ .Path }}": "content"})
+		_, err := planFiles(context.Background(), []*component{c}, map[string]string{"Path": name}, nil, "")
+		if err == nil || !strings.Contains(err.Error(), "refusing to write template file") {
+			t.Errorf("planFiles naming a file %s = %v, want a refusal", name, err)
+		}
+	}
+}
+
+func TestGenerateFileRefusesSymlinks(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "id_rsa")
+	if err := os.WriteFile(secret, []byte("PRIVATE KEY"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := testComponent(t, map[string]string{"config.txt": "content"})
+	files, err := planFiles(context.Background(), []*component{c}, nil, nil, "")
+	if err != nil || len(files) != 1 {
+		t.Fatalf("planFiles = %v, %v, want a single file", files, err)
+	}
+
+	// The file is swapped for a symlink once planned.
+	if err := os.Remove(files[0].src); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, files[0].src); err != nil {
+		t.Fatal(err)
+	}
+	data, err := generateFile(context.Background(), files[0], nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Fatalf("generateFile of a symlink = %q, %v, want a not a regular file error", data, err)
+	}
+}
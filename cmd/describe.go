@@ -0,0 +1,231 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/betterde/gonew/internal/cache"
+	"github.com/betterde/gonew/internal/goproxy"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
+)
+
+var refreshManifest bool
+
+// describeCmd represents the describe command
+var describeCmd = &cobra.Command{
+	Use:   "describe <src>[+<src>...]",
+	RunE:  describeTemplate,
+	Args:  cobra.ExactArgs(1),
+	Short: "Show the manifest of a template without generating a project",
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+
+	describeCmd.Flags().BoolVar(&refreshManifest, "refresh", false, "Ignore the cached manifest and download the template")
+}
+
+func describeTemplate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	components, err := parseSources(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for i, c := range components {
+		config, err := describeComponent(ctx, c)
+		if err != nil {
+			return err
+		}
+		if len(components) > 1 {
+			if i > 0 {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Template:    %s\n", c)
+		}
+		printManifest(cmd, config)
+	}
+	return nil
+}
+
+// describeComponent returns the manifest of c, resolving its source as init
+// does: modules only need their manifest, the other sources are read in
+// place, checked out or extracted.
+func describeComponent(ctx context.Context, c *component) (*project.Config, error) {
+	if _, ok := c.source.(moduleSource); ok {
+		return fetchManifest(ctx, c.mod, c.query, refreshManifest)
+	}
+	info, err := c.source.resolve(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := project.Find(info.Dir)
+	return config, err
+}
+
+// fetchManifest returns the manifest of mod at query, preferring the local
+// manifest cache, then reading only the manifest out of the module zip when
+// the proxy serves byte ranges, and falling back to downloading the module.
+// Manifests read from the zip are only cached when the go command would not
+// check the module against the checksum database either, as the cache is
+// trusted like the downloads it otherwise holds.
+func fetchManifest(ctx context.Context, mod, query string, refresh bool) (*project.Config, error) {
+	if !refresh {
+		config, err := cache.LoadManifest(mod, query)
+		if err == nil {
+			return config, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	config, manifest, version, trusted, err := proxyManifest(ctx, mod, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		info, err := downloadModule(ctx, mod+"@"+query)
+		if err != nil {
+			return nil, err
+		}
+		if config, manifest, err = project.Find(info.Dir); err != nil {
+			return nil, err
+		}
+		version, trusted = info.Version, true
+	}
+	if !trusted {
+		return config, nil
+	}
+	if err := cache.SaveManifest(mod, manifest, query, version); err != nil {
+		log.Printf("caching manifest: %v", err)
+	}
+	return config, nil
+}
+
+// proxyManifest reads the manifest of mod@query out of the module zip served
+// by the first proxy of GOPROXY, fetching only the parts of the zip it needs,
+// and returns it with the version the query stands for. Like the go command,
+// with the settings of the template flags, it does not use the proxy for
+// modules matching GONOPROXY. The zip is not checked against the checksum
+// database: trusted reports whether the go command would skip it for mod
+// too, making the manifest as trustworthy as a download.
+func proxyManifest(ctx context.Context, mod, query string) (config *project.Config, manifest []byte, version string, trusted bool, err error) {
+	cmd := exec.CommandContext(ctx, "go", "env", "-json", "GOPROXY", "GONOPROXY", "GOSUMDB", "GONOSUMDB")
+	cmd.Env = append(os.Environ(), fetchEnv()...)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+	var env map[string]string
+	if err := json.Unmarshal(out, &env); err != nil {
+		return nil, nil, "", false, fmt.Errorf("go env: %v", err)
+	}
+	if module.MatchPrefixPatterns(env["GONOPROXY"], mod) {
+		return nil, nil, "", false, fmt.Errorf("%s is not fetched through a proxy", mod)
+	}
+	proxies := goproxy.Parse(env["GOPROXY"])
+	if len(proxies) == 0 || !goproxy.IsHTTP(proxies[0]) {
+		return nil, nil, "", false, fmt.Errorf("no HTTP proxy in GOPROXY")
+	}
+	trusted = env["GOSUMDB"] == "off" || module.MatchPrefixPatterns(env["GONOSUMDB"], mod)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	version, err = goproxy.Resolve(ctx, client, proxies[0], mod, query)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+	zr, err := goproxy.OpenZip(ctx, client, proxies[0], mod, version)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+	root, err := fs.Sub(zr, mod+"@"+version)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+	config, manifest, err = project.FindFS(root)
+	return config, manifest, version, trusted, err
+}
+
+// printManifest writes a human-readable summary of the manifest.
+func printManifest(cmd *cobra.Command, config *project.Config) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Name:        %s\n", config.Name)
+	fmt.Fprintf(out, "Description: %s\n", config.Desc)
+	if config.State != "" {
+		fmt.Fprintf(out, "State:       %s", config.State)
+		if config.Replacement != "" {
+			fmt.Fprintf(out, " [replacement: %s]", config.Replacement)
+		}
+		fmt.Fprintln(out)
+	}
+	if len(config.Variables) > 0 {
+		fmt.Fprintln(out, "Variables:")
+		for _, variable := range config.Variables {
+			fmt.Fprintf(out, "  %-20s %s", variable.Name, variable.Placeholder)
+			if variable.Default != "" {
+				fmt.Fprintf(out, " [default: %s]", variable.Default)
+			}
+			if variable.Example != "" {
+				fmt.Fprintf(out, " [example: %s]", variable.Example)
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	if len(config.Features) > 0 {
+		fmt.Fprintln(out, "Features:")
+		for _, feature := range config.Features {
+			fmt.Fprintf(out, "  %-20s %s", feature.Name, feature.Description)
+			if feature.Default {
+				fmt.Fprint(out, " [default]")
+			}
+			fmt.Fprintln(out)
+		}
+	}
+	if len(config.Hooks.PreInit) > 0 || len(config.Hooks.PostInit) > 0 {
+		fmt.Fprintln(out, "Hooks:")
+		printHooks(out, "pre_init", config.Hooks.PreInit)
+		printHooks(out, "post_init", config.Hooks.PostInit)
+	}
+}
+
+// printHooks lists the commands of the hooks run at stage.
+func printHooks(out io.Writer, stage string, hooks []project.Hook) {
+	for _, hook := range hooks {
+		fmt.Fprintf(out, "  %-20s %s", stage, hook.Command)
+		if hook.Timeout != "" {
+			fmt.Fprintf(out, " [timeout: %s]", hook.Timeout)
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/betterde/gonew/internal/cache"
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/spf13/cobra"
+)
+
+func TestDescribeLocalTemplate(t *testing.T) {
+	c := testComponent(t, map[string]string{
+		"go.mod": "module example.com/service\n",
+		"template.yaml": `name: service
+description: A service
+hooks:
+  pre_init:
+    - command: docker version
+      timeout: 10s
+  post_init:
+    - command: git init
+`,
+	})
+	components, err := parseSources(t.Context(), c.root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := describeComponent(t.Context(), components[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	printManifest(cmd, config)
+	for _, want := range []string{
+		"Name:        service",
+		"pre_init             docker version [timeout: 10s]",
+		"post_init            git init",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("describe output misses %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFetchManifestProxyZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"example.com/tpl@v1.0.0/go.mod":        "module example.com/tpl\n",
+		"example.com/tpl@v1.0.0/template.yaml": "name: tpl\ndesc: From the zip\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/tpl/@v/v1.0.0.info":
+			w.Write([]byte(`{"Version":"v1.0.0"}`))
+		case "/example.com/tpl/@v/v1.0.0.zip":
+			// ServeContent answers range requests.
+			http.ServeContent(w, r, "v1.0.0.zip", time.Time{}, bytes.NewReader(buf.Bytes()))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv(paths.HomeEnv, t.TempDir())
+	t.Setenv("GOPROXY", srv.URL)
+	t.Setenv("GOFLAGS", "")
+	for _, name := range []string{"GONOPROXY", "GOPRIVATE", "GONOSUMDB"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GOSUMDB", "sum.golang.org")
+	defer func(sumdb, nosumdb string) { templateSumDB, templateNoSumDB = sumdb, nosumdb }(templateSumDB, templateNoSumDB)
+
+	tests := []struct {
+		sumdb, nosumdb string
+		cached         bool
+	}{
+		// The zip is not checked like go mod download checks modules.
+		{"", "", false},
+		{"off", "", true},
+		{"", "example.com/*", true},
+		{"", "example.com/other", false},
+	}
+	for _, tt := range tests {
+		templateSumDB, templateNoSumDB = tt.sumdb, tt.nosumdb
+		os.RemoveAll(os.Getenv(paths.HomeEnv))
+		config, err := fetchManifest(t.Context(), "example.com/tpl", "v1.0.0", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Desc != "From the zip" {
+			t.Errorf("desc = %q, want the manifest of the zip", config.Desc)
+		}
+		_, err = cache.LoadManifest("example.com/tpl", "v1.0.0")
+		if cached := err == nil; cached != tt.cached {
+			t.Errorf("--template-sumdb=%q --template-nosumdb=%q: manifest cached = %v (%v), want %v", tt.sumdb, tt.nosumdb, cached, err, tt.cached)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/normalize"
+)
+
+// dryRun, set by --dry-run, prints what init would generate instead of
+// writing it.
+var dryRun bool
+
+// plannedChange is what generating a file would do, as printed by
+// --dry-run.
+type plannedChange struct {
+	Path string `json:"path"`
+	// Action is create, or merge for a file several sources generate.
+	Action      string          `json:"action"`
+	Imports     []importRewrite `json:"imports,omitempty"`
+	Expressions []expansion     `json:"expressions,omitempty"`
+}
+
+// importRewrite is a Go import path the module path rewriting changes.
+type importRewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// expansion is a template expression of a file and, unless it is an
+// action such as if or range, the text it expands to.
+type expansion struct {
+	Expr  string `json:"expr"`
+	Value string `json:"value,omitempty"`
+}
+
+// expressionPattern matches the template expressions between left and
+// right, {{ and }} for both engines by default.
+func expressionPattern(left, right string) *regexp.Regexp {
+	return regexp.MustCompile(`(?s)` + regexp.QuoteMeta(left) + `.*?` + regexp.QuoteMeta(right))
+}
+
+// controlWords start the template actions that do not expand to text.
+var controlWords = []string{"if", "else", "end", "range", "with", "define", "block", "template", "break", "continue", "/*"}
+
+// planChanges generates files in memory and returns what generating each
+// would do.
+func planChanges(ctx context.Context, files []*plannedFile, inputs map[string]string, features map[string]bool) ([]plannedChange, error) {
+	var changes []plannedChange
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		src, err := os.ReadFile(file.src)
+		if err != nil {
+			return nil, err
+		}
+		out, err := generateFile(ctx, file, inputs, features)
+		if err != nil {
+			return nil, err
+		}
+
+		change := plannedChange{Path: filepath.ToSlash(file.dstRel), Action: "create"}
+		if seen[file.dstRel] {
+			change.Action = "merge"
+		}
+		seen[file.dstRel] = true
+
+		c := file.component
+		if strings.HasSuffix(file.dstRel, ".go") && !c.config.Cookiecutter {
+			change.Imports = rewrittenImports(src, out, c.rootMod)
+		}
+		if normalize.IsBinaryFile(file.rel, src) || glob.MatchAny(c.config.RenderExclude, filepath.ToSlash(file.rel)) {
+			changes = append(changes, change)
+			continue
+		}
+		data := file.item.context(c.context(inputs, features))
+		left, right := c.config.Delims()
+		for _, expr := range expressionPattern(left, right).FindAllString(string(src), -1) {
+			e := expansion{Expr: expr}
+			if !isControl(expr, left, right) {
+				if value, err := c.engine.Render(change.Path, expr, data); err == nil {
+					e.Value = string(value)
+				}
+			}
+			change.Expressions = append(change.Expressions, e)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// isControl reports whether expr, between left and right, is an action
+// rather than a value.
+func isControl(expr, left, right string) bool {
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, left), right)
+	expr = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(expr, "-"), "-"))
+	word, _, _ := strings.Cut(expr, " ")
+	for _, w := range controlWords {
+		if word == w {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrittenImports returns the imports of the Go source src under srcMod
+// with the paths they have in the generated out. Files that do not parse,
+// as templated ones may not, have none.
+func rewrittenImports(src, out []byte, srcMod string) []importRewrite {
+	before, err := importPaths(src)
+	if err != nil {
+		return nil
+	}
+	after, err := importPaths(out)
+	if err != nil || len(after) != len(before) {
+		return nil
+	}
+	var rewrites []importRewrite
+	for i, from := range before {
+		if from != after[i] && (from == srcMod || strings.HasPrefix(from, srcMod+"/")) {
+			rewrites = append(rewrites, importRewrite{From: from, To: after[i]})
+		}
+	}
+	return rewrites
+}
+
+// importPaths returns the import paths of the Go source data, in order.
+func importPaths(data []byte) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", data, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(f.Imports))
+	for i, spec := range f.Imports {
+		paths[i], _ = strconv.Unquote(spec.Path.Value)
+	}
+	return paths, nil
+}
+
+// printChanges writes the changes for people, or as JSON.
+func printChanges(w io.Writer, dir string, changes []plannedChange, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(changes)
+	}
+
+	fmt.Fprintf(w, "Dry run, nothing is written to %s:\n", dir)
+	for _, change := range changes {
+		fmt.Fprintf(w, "%-6s %s\n", change.Action, change.Path)
+		for _, rewrite := range change.Imports {
+			fmt.Fprintf(w, "       import %s -> %s\n", rewrite.From, rewrite.To)
+		}
+		for _, e := range change.Expressions {
+			if e.Value == "" {
+				fmt.Fprintf(w, "       %s\n", e.Expr)
+				continue
+			}
+			fmt.Fprintf(w, "       %s -> %q\n", e.Expr, e.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/betterde/gonew/pkg/progress"
+)
+
+// eventsFile is where progress events are written, "-" for stdout.
+var eventsFile string
+
+// emit receives the progress events of init.
+var emit progress.Handler = progress.Discard
+
+// openEvents points emit at the handler carried by ctx, if any, and at
+// eventsFile, if given, and returns a function closing it.
+func openEvents(ctx context.Context) (func(), error) {
+	var handlers []progress.Handler
+	if h := progress.FromContext(ctx); h != nil {
+		handlers = append(handlers, h)
+	}
+	closeFile := func() {}
+	switch eventsFile {
+	case "":
+	case "-":
+		// Stdout carries the --json summary, which events would corrupt.
+		if jsonOutput {
+			return nil, fmt.Errorf("--events - cannot be combined with --json, write the events to a file")
+		}
+		handlers = append(handlers, progress.JSONLines(os.Stdout))
+	default:
+		f, err := os.OpenFile(eventsFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, progress.JSONLines(f))
+		closeFile = func() { f.Close() }
+	}
+	if len(handlers) > 0 {
+		emit = progress.Tee(handlers...)
+	}
+	return func() {
+		emit = progress.Discard
+		closeFile()
+	}, nil
+}
+
+func emitPhase(phase string) {
+	emit(progress.Event{Kind: progress.KindPhase, Time: time.Now(), Phase: phase})
+}
+
+// outputEvents is a writer emitting every line written to it as an output event.
+type outputEvents struct {
+	buf []byte
+}
+
+func (w *outputEvents) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		emit(progress.Event{Kind: progress.KindOutput, Time: time.Now(), Text: string(w.buf[:i])})
+		w.buf = w.buf[i+1:]
+	}
+}
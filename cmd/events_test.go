@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/betterde/gonew/pkg/progress"
+)
+
+func TestOpenEventsContextHandler(t *testing.T) {
+	var got []progress.Event
+	ctx := progress.NewContext(context.Background(), func(e progress.Event) {
+		got = append(got, e)
+	})
+	closeEvents, err := openEvents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emitPhase(progress.PhasePlan)
+	closeEvents()
+	emitPhase(progress.PhaseDone)
+
+	if len(got) != 1 || got[0].Kind != progress.KindPhase || got[0].Phase != progress.PhasePlan {
+		t.Errorf("events = %+v, want the plan phase only", got)
+	}
+}
+
+func TestOpenEventsStdoutWithJSON(t *testing.T) {
+	defer func(file string, json bool) { eventsFile, jsonOutput = file, json }(eventsFile, jsonOutput)
+	eventsFile, jsonOutput = "-", true
+	if _, err := openEvents(context.Background()); err == nil {
+		t.Error("openEvents accepted --events - with --json")
+	}
+}
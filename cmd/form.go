@@ -0,0 +1,152 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/pkg/progress"
+	"github.com/betterde/gonew/pkg/validate"
+	"github.com/manifoldco/promptui"
+)
+
+// runForm asks for the pending variables in one form: a list of the
+// variables and their current values, where choosing one edits it and the
+// last item submits. Defaults are worked out once, in order as when
+// prompting, from the answers before them; conditions follow every edit:
+// variables whose when does not hold are left out of the form and answered
+// with "".
+func runForm(ctx context.Context, config *project.Config, pending []project.Variable, answers map[string]string, features map[string]bool) (map[string]string, error) {
+	rules := make([]validate.Rule, len(pending))
+	for i, variable := range pending {
+		rule, err := variable.Rule()
+		if err != nil {
+			return nil, err
+		}
+		rules[i] = rule
+	}
+
+	values := make(map[string]string, len(pending))
+	var shown []int
+	cursor := 0
+	for {
+		current := make(map[string]string, len(answers)+len(pending))
+		for name, value := range answers {
+			current[name] = value
+		}
+		shown = shown[:0]
+		for i, variable := range pending {
+			if asked, err := variable.Asked(config.Vars(current, features)); err != nil {
+				return nil, err
+			} else if !asked {
+				current[variable.Name] = ""
+				continue
+			}
+			if _, ok := values[variable.Name]; !ok {
+				// Values are worked out once, when the variable first shows
+				// up: generated values are not generated again and ports are
+				// not allocated again with every edit.
+				var value string
+				var err error
+				if variable.Generated != "" {
+					value, err = render.Generate(variable.Name, variable.Generated)
+				} else {
+					value, err = defaultValue(ctx, config, variable, current, features)
+				}
+				if err != nil {
+					return nil, err
+				}
+				values[variable.Name] = value
+			}
+			current[variable.Name] = values[variable.Name]
+			if variable.Generated != "" {
+				continue
+			}
+			shown = append(shown, i)
+		}
+		if len(shown) == 0 {
+			return current, nil
+		}
+
+		items := make([]string, 0, len(shown)+1)
+		for _, i := range shown {
+			items = append(items, fmt.Sprintf("%s: %s", formLabel(pending[i]), values[pending[i].Name]))
+		}
+		items = append(items, "Submit")
+
+		prompt := promptui.Select{
+			Label:     "Review the answers, choose one to edit",
+			Items:     items,
+			Size:      len(items),
+			CursorPos: min(cursor, len(shown)),
+		}
+		// Without input the form is submitted as it is.
+		choice, err := runSelect(&prompt, len(shown))
+		if err != nil {
+			return nil, err
+		}
+
+		if choice == len(shown) {
+			// Submitting with invalid answers goes on to edit the first of them.
+			choice = -1
+			for j, i := range shown {
+				variable := pending[i]
+				err := rules[i].Validate(values[variable.Name])
+				if err == nil {
+					err = checkRemote(ctx, variable, values[variable.Name])
+				}
+				if err != nil {
+					log.Printf("%s: %v", formLabel(variable), err)
+					if choice < 0 {
+						choice = j
+					}
+				}
+			}
+			if choice < 0 {
+				return current, nil
+			}
+		}
+
+		i := shown[choice]
+		variable := pending[i]
+		emit(progress.Event{Kind: progress.KindPrompt, Time: time.Now(), Variable: variable.Name})
+		value, err := promptVariable(variable, formLabel(variable), values[variable.Name], rules[i])
+		if err != nil {
+			return nil, err
+		}
+		values[variable.Name] = value
+		cursor = choice
+	}
+}
+
+// formLabel returns the label of variable in the form.
+func formLabel(variable project.Variable) string {
+	if variable.Placeholder != "" {
+		return variable.Placeholder
+	}
+	return variable.Name
+}
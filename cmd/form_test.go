@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/betterde/gonew/internal/project"
+)
+
+// withInput runs f with os.Stdin reading input and accessible prompts.
+func withInput(t *testing.T, input string, f func()) {
+	t.Helper()
+	name := t.TempDir() + "/stdin"
+	if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	defer func(stdin, stdout *os.File, a bool) { os.Stdin, os.Stdout, accessible = stdin, stdout, a }(os.Stdin, os.Stdout, accessible)
+	os.Stdin, accessible = in, true
+	if os.Stdout, err = os.Open(os.DevNull); err != nil {
+		t.Fatal(err)
+	}
+	f()
+}
+
+func TestRunPromptsFormConditions(t *testing.T) {
+	defer func(v bool) { formMode = v }(formMode)
+	formMode = true
+	config := &project.Config{Variables: []project.Variable{
+		{Name: "UseDatabase", Type: "bool", Default: "true"},
+		{Name: "DSN", Default: "postgres://localhost", When: "UseDatabase == true"},
+		{Name: "Token", Generated: "randAlphaNum 8", When: "UseDatabase == true"},
+	}}
+	tests := []struct {
+		input string
+		want  map[string]string
+	}{
+		// Submitted as it is.
+		{"\n", map[string]string{"UseDatabase": "true", "DSN": "postgres://localhost"}},
+		// UseDatabase edited to no, which leaves DSN and Token out.
+		{"1\nn\n\n", map[string]string{"UseDatabase": "false", "DSN": "", "Token": ""}},
+	}
+	for _, tt := range tests {
+		var got map[string]string
+		var err error
+		withInput(t, tt.input, func() {
+			got, err = runPrompts(t.Context(), config, nil, nil)
+		})
+		if err != nil {
+			t.Errorf("input %q: %v", tt.input, err)
+			continue
+		}
+		for name, want := range tt.want {
+			if got[name] != want {
+				t.Errorf("input %q: %s = %q, want %q", tt.input, name, got[name], want)
+			}
+		}
+		if got["UseDatabase"] == "true" && len(got["Token"]) != 8 {
+			t.Errorf("input %q: Token = %q, want it generated", tt.input, got["Token"])
+		}
+	}
+}
+
+func TestRunPromptsFormGivenAnswers(t *testing.T) {
+	defer func(v bool) { formMode = v }(formMode)
+	formMode = true
+	config := &project.Config{Variables: []project.Variable{
+		{Name: "UseDatabase", Type: "bool", Default: "false"},
+		{Name: "DSN", When: "UseDatabase == true"},
+	}}
+	// An empty DSN is fine as long as the form leaves it out.
+	withInput(t, "\n", func() {
+		if _, err := runPrompts(t.Context(), config, map[string]string{"DSN": ""}, nil); err != nil {
+			t.Errorf("empty answer to a variable left out: %v", err)
+		}
+	})
+	withInput(t, "1\ny\n\n", func() {
+		_, err := runPrompts(t.Context(), config, map[string]string{"DSN": ""}, nil)
+		if err == nil || !strings.Contains(err.Error(), "DSN") {
+			t.Errorf("empty answer to a required variable asked = %v, want an error", err)
+		}
+	})
+}
+
+func TestRunPromptsFormDefaultsOnce(t *testing.T) {
+	defer func(v bool) { formMode = v }(formMode)
+	formMode = true
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	busy := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	config := &project.Config{Variables: []project.Variable{
+		{Name: "Name", Default: "app"},
+		{Name: "Port", Type: "port", Default: busy, Allocate: true},
+		{Name: "Database", Default: "{{ .Name }}_db"},
+	}}
+
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+	var got map[string]string
+	// Name edited before submitting.
+	withInput(t, "1\nshop\n\n", func() {
+		got, err = runPrompts(t.Context(), config, nil, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(logged.String(), "is in use"); n != 1 {
+		t.Errorf("port allocated %d times, want once:\n%s", n, logged.String())
+	}
+	if got["Port"] == busy || got["Port"] == "" {
+		t.Errorf("Port = %q, want a free port other than %s", got["Port"], busy)
+	}
+	if got["Name"] != "shop" || got["Database"] != "app_db" {
+		t.Errorf("Name, Database = %q, %q, want shop, app_db", got["Name"], got["Database"])
+	}
+}
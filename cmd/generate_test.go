@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// largeTemplateFiles returns the files of a synthetic template of n packages,
+// each a Go file importing another package of the template and rendering
+// answers, under a templated directory name.
+func largeTemplateFiles(n int) map[string]string {
+	files := map[string]string{
+		"go.mod": "module example.com/template\n\ngo 1.24\n",
+	}
+	for i := range n {
+		files[fmt.Sprintf("internal/{{ .Name }}%d/handler.go", i)] = fmt.Sprintf(`package handler%d
+
+import (
+	"fmt"
+
+	"example.com/template/internal/shared"
+)
+
+// Handler serves {{ .Name }}.
+func Handler() string {
+	return fmt.Sprint(shared.Prefix, "{{ upper .Name }}")
+}
+{{ if .Enabled }}
+func Enabled() bool { return true }
+{{ end }}`, i)
+	}
+	return files
+}
+
+func benchmarkComponent(b *testing.B) (*component, map[string]string) {
+	c := testComponent(b, largeTemplateFiles(500))
+	c.rootMod = "example.com/template"
+	saved := dstMod
+	dstMod = "example.com/project"
+	b.Cleanup(func() { dstMod = saved })
+	return c, map[string]string{"Name": "service", "Enabled": "true"}
+}
+
+func BenchmarkPlanFiles(b *testing.B) {
+	c, inputs := benchmarkComponent(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := planFiles(context.Background(), []*component{c}, inputs, nil, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateFiles(b *testing.B) {
+	c, inputs := benchmarkComponent(b)
+	files, err := planFiles(context.Background(), []*component{c}, inputs, nil, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, file := range files {
+			if _, err := generateFile(context.Background(), file, inputs, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
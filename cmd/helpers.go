@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/project"
+)
+
+// runHelpers builds and runs the helper program of each component that has
+// one, in project.HelpersDir. The program reads the rendering context as a
+// JSON object on stdin and writes a JSON object on stdout, whose keys are
+// added to the context of the component's files.
+func runHelpers(ctx context.Context, components []*component, inputs map[string]string, features map[string]bool) error {
+	for _, c := range components {
+		dir := filepath.Join(c.root, filepath.FromSlash(project.HelpersDir))
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if noHooks {
+			return fmt.Errorf("%s needs to run its helper program, which --no-hooks does not allow", c)
+		}
+		// A dry run runs no template code, as it skips the hooks, and its
+		// files cannot be rendered without the helper's values.
+		if dryRun {
+			return fmt.Errorf("%s needs to run its helper program, which --dry-run does not allow", c)
+		}
+		values, err := runHelper(ctx, dir, c.context(inputs, features))
+		if err != nil {
+			return fmt.Errorf("%s: helper: %v", c, err)
+		}
+		c.helper = values
+	}
+	return nil
+}
+
+// runHelper builds the Go program in dir and runs it with data.
+func runHelper(ctx context.Context, dir string, data map[string]any) (map[string]any, error) {
+	tmp, err := os.MkdirTemp("", "gonew-helper-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	log.Printf("building helper %s", dir)
+	bin := filepath.Join(tmp, "helper")
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, ".")
+	build.Dir = dir
+	if output, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("building: %v\n%s", err, output)
+	}
+
+	input, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, project.DefaultHookTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	run := exec.CommandContext(ctx, bin)
+	run.Dir = dir
+	run.Stdin = bytes.NewReader(input)
+	run.Stdout = &stdout
+	run.Stderr = &stderr
+	if err := run.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", project.DefaultHookTimeout)
+		}
+		return nil, fmt.Errorf("%v\n%s", err, stderr.Bytes())
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &values); err != nil {
+		return nil, fmt.Errorf("output is not a JSON object: %v", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunHelpersDryRun(t *testing.T) {
+	defer func(v bool) { dryRun = v }(dryRun)
+	dryRun = true
+	c := testComponent(t, map[string]string{
+		".gonew/helpers/main.go": "package main\n\nfunc main() { panic(\"helper ran\") }\n",
+	})
+	err := runHelpers(t.Context(), []*component{c}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "--dry-run") {
+		t.Errorf("runHelpers under --dry-run: %v, want it refused", err)
+	}
+	if c.helper != nil {
+		t.Error("runHelpers under --dry-run set helper values")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/betterde/gonew/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var showAnswers bool
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	RunE:  listHistory,
+	Args:  cobra.NoArgs,
+	Short: "List the projects generated with gonew",
+}
+
+// purgeCmd represents the history purge command
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	RunE:  purgeHistory,
+	Args:  cobra.NoArgs,
+	Short: "Delete the history and its encryption key",
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(purgeCmd)
+
+	historyCmd.Flags().BoolVar(&showAnswers, "answers", false, "Show the answers given for each project")
+}
+
+func listHistory(cmd *cobra.Command, args []string) error {
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, entry := range entries {
+		fmt.Fprintf(out, "%s  %s  %s  %s\n", entry.Time.Local().Format(time.DateTime), entry.Module, entry.Dir, entry.Template)
+		if !showAnswers {
+			continue
+		}
+		names := make([]string, 0, len(entry.Answers))
+		for name := range entry.Answers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "    %s: %s\n", name, entry.Answers[name])
+		}
+	}
+	return nil
+}
+
+func purgeHistory(cmd *cobra.Command, args []string) error {
+	if err := history.Purge(); err != nil {
+		return err
+	}
+	log.Printf("history purged")
+	return nil
+}
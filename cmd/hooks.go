@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/project"
+)
+
+// noHooks, set by --no-hooks, skips the commands templates declare as
+// hooks, for users who do not want a template to run anything.
+var noHooks bool
+
+// runHooks runs the hooks of each component in dir, in order, with their
+// commands and messages rendered like template files. The first hook
+// failing or timing out stops the others.
+func runHooks(ctx context.Context, dir string, components []*component, hooks func(*project.Config) []project.Hook, inputs map[string]string, features map[string]bool) error {
+	for _, c := range components {
+		for i, hook := range hooks(c.config) {
+			if noHooks {
+				log.Printf("skipping hook %s of %s", hook, c)
+				continue
+			}
+			data := c.context(inputs, features)
+			rendered, err := c.engine.Render(fmt.Sprintf("hook %d", i+1), hook.Command, data)
+			if err != nil {
+				return &templateError{component: c, file: project.FileName, err: err}
+			}
+			command := strings.TrimSpace(string(rendered))
+			err = checkHookEnv(hook.Env)
+			if err == nil {
+				err = runHook(ctx, dir, command, hook.Duration())
+			}
+			if err == nil {
+				continue
+			}
+			if hook.Message == "" {
+				return fmt.Errorf("%s: hook %s: %v", c, hook, err)
+			}
+			message, rerr := c.engine.Render(fmt.Sprintf("hook %d message", i+1), hook.Message, data)
+			if rerr != nil {
+				return &templateError{component: c, file: project.FileName, err: rerr}
+			}
+			return fmt.Errorf("%s: %s (hook %s: %v)", c, strings.TrimSpace(string(message)), hook, err)
+		}
+	}
+	return nil
+}
+
+// checkHookEnv reports the first of names not set in the environment.
+func checkHookEnv(names []string) error {
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			return fmt.Errorf("%s is not set", name)
+		}
+	}
+	return nil
+}
+
+// runHook runs command in dir, stopping it after timeout, with its output
+// on stderr and sent as output events.
+func runHook(ctx context.Context, dir, command string, timeout time.Duration) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	log.Printf("running hook: %s", command)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	process := exec.CommandContext(ctx, args[0], args[1:]...)
+	process.Dir = dir
+	output := io.MultiWriter(os.Stderr, &outputEvents{})
+	process.Stdout = output
+	process.Stderr = output
+	err := process.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
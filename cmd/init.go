@@ -23,38 +23,74 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/betterde/gonew/internal/edit"
+	"github.com/betterde/gonew/internal/answers"
+	"github.com/betterde/gonew/internal/cache"
+	"github.com/betterde/gonew/internal/cloud"
+	"github.com/betterde/gonew/internal/git"
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/header"
+	"github.com/betterde/gonew/internal/history"
+	"github.com/betterde/gonew/internal/normalize"
+	"github.com/betterde/gonew/internal/ports"
 	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/internal/safepath"
+	"github.com/betterde/gonew/internal/sandbox"
+	"github.com/betterde/gonew/pkg/progress"
+	"github.com/betterde/gonew/pkg/rewrite"
+	"github.com/betterde/gonew/pkg/validate"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
-	"go/parser"
-	"go/token"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
-	"gopkg.in/yaml.v3"
+	"io"
 	"io/fs"
 	"log"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-	"text/template"
+	"time"
 )
 
 var (
-	srcMod string
-	dstMod string
-	config *project.Config
+	dstMod      string
+	config      *project.Config
+	promptFirst bool
+	targetDir   string
+	baseDir     string
+	limits      sizeLimits
+	importFile  string
+	answerFiles []string
+	valuesFiles []string
+	varFlags    []string
+	featureList []string
+	layoutName  string
+	lineEndings string
+	stripBOM    bool
+	auditLog    string
+	mtimeMode   string
+	jsonOutput  bool
+	formMode    bool
+	noHistory   bool
+	saveAnswers bool
+	onlyGlobs   []string
+	modelsFile  string
+	delimiters  []string
 )
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
-	Use:   "init <src> [dst]",
-	Run:   initProject,
+	Use:   "init <src>[+<src>...] [dst] [dir]",
+	RunE:  initProject,
 	Args:  cobra.MinimumNArgs(1),
 	Short: "Initialize a new project using a template",
 }
@@ -62,304 +98,1424 @@ var initCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(initCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// initCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// initCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	initCmd.Flags().DurationVar(&promptTimeout, "prompt-timeout", 0, "Accept the default of a prompt left unanswered this long, fail if it has none")
+	initCmd.Flags().BoolVar(&formMode, "form", false, "Answer all variables in one form, in any order, and review them before submitting")
+	initCmd.Flags().BoolVar(&promptFirst, "prompt-first", false, "Answer prompts from the cached template manifest before downloading")
+	initCmd.Flags().StringVar(&targetDir, "dir", "", "Directory to generate the project in, defaults to the last element of dst")
+	initCmd.Flags().StringVar(&baseDir, "base-dir", "", "Reject target directories that resolve outside this directory")
+	initCmd.Flags().StringVar(&importFile, "import-answers", "", "Seed answers from a copier (.copier-answers.yml) or yeoman (.yo-rc.json) answers file")
+	initCmd.Flags().StringArrayVar(&varFlags, "var", nil, "Answer a variable as name=value, may be repeated and overrides answer files")
+	initCmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "Read the canonical answers of the project, such as a checked-in values.yaml, from a YAML or JSON file or URL, may be repeated; --answers and --var override them")
+	initCmd.Flags().StringArrayVar(&answerFiles, "answers", nil, "Read answers from a YAML or JSON file or URL, may be repeated with later files deep-merged over earlier ones")
+	initCmd.Flags().StringSliceVar(&featureList, "feature", nil, "Select a template feature without prompting, may be repeated")
+	initCmd.Flags().StringSliceVar(&onlyGlobs, "only", nil, "Generate only the files whose destination path matches these globs, such as cmd/**,Makefile, into a new or existing directory")
+	initCmd.Flags().StringVar(&modelsFile, "models", "", "Read the models to generate from this YAML file instead of the template's")
+	initCmd.Flags().StringSliceVar(&delimiters, "delimiters", nil, "Left and right action delimiters of the template, such as '[[,]]', overriding its delimiters")
+	initCmd.Flags().StringVar(&layoutName, "layout", "", "Directory layout to generate, for templates offering several")
+	initCmd.Flags().StringVar(&lineEndings, "line-endings", "", "Convert line endings of generated text files to lf, crlf or native, overriding the template")
+	initCmd.Flags().BoolVar(&stripBOM, "strip-bom", false, "Strip UTF-8 byte order marks from generated text files")
+	initCmd.Flags().StringVar(&mtimeMode, "mtime", mtimeNow, "Modification time of generated files: now, source (the template file's) or epoch ($SOURCE_DATE_EPOCH, else 1970-01-01)")
+	initCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the files that would be generated, the imports rewritten and the template expressions expanded, without writing anything")
+	initCmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Do not run the commands the template declares as hooks")
+	initCmd.Flags().BoolVar(&failOnWarnings, "fail-on-warnings", false, "Exit with an error once the project is generated if any warning was reported, such as an unused variable")
+	initCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print a JSON summary of the generated project, its files, messages and artifacts")
+	initCmd.Flags().StringVar(&errorReport, "error-report", "", "Write a sanitized report of template errors to this file without asking")
+	initCmd.Flags().StringVar(&eventsFile, "events", "", "Write progress events as JSON lines to this file, - for stdout")
+	initCmd.Flags().BoolVar(&saveAnswers, "save-answers", false, "Write the answers, without secret and generated ones, to gonew.answers.yaml in the project")
+	initCmd.Flags().BoolVar(&noHistory, "no-history", false, "Do not record the project and its answers in the encrypted history")
+	initCmd.Flags().StringVar(&auditLog, "audit-log", "", "Append a JSON line for every file written to this file")
+	initCmd.Flags().IntVar(&limits.MaxFiles, "max-files", 0, "Maximum number of files in the template, 0 means unlimited")
+	initCmd.Flags().Int64Var(&limits.MaxTotalSize, "max-total-size", 0, "Maximum total size of the template in bytes, 0 means unlimited")
+	initCmd.Flags().Int64Var(&limits.MaxFileSize, "max-file-size", 0, "Maximum size of a single template file in bytes, 0 means unlimited")
 }
 
-func initProject(cmd *cobra.Command, args []string) {
+func initProject(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
 	if len(args) < 1 || len(args) > 3 {
 		err := cmd.Usage()
 		if err != nil {
-			return
+			return err
 		}
 	}
 
-	srcMod = args[0]
-	ver := srcMod
-	if !strings.Contains(ver, "@") {
-		ver += "@latest"
+	components, err := parseSources(ctx, args[0])
+	if err != nil {
+		return err
 	}
-
-	srcMod, _, _ = strings.Cut(srcMod, "@")
-	if err := module.CheckPath(srcMod); err != nil {
-		log.Fatalf("invalid source module name: %v", err)
+	if err := normalize.CheckLineEndings(lineEndings); err != nil {
+		return err
+	}
+	if mtimeMode != mtimeNow && mtimeMode != mtimeSource && mtimeMode != mtimeEpoch {
+		return fmt.Errorf("invalid --mtime %s, must be one of now, source or epoch", mtimeMode)
+	}
+	if createPR && scaffoldBranch == "" {
+		return errors.New("--create-pr needs --branch")
+	}
+	if dryRun && scaffoldBranch != "" {
+		return errors.New("--dry-run cannot be combined with --branch")
+	}
+	if verifyImage != "" {
+		if err := checkContainerRuntime(); err != nil {
+			return err
+		}
+	}
+	closeEvents, err := openEvents(ctx)
+	if err != nil {
+		return err
 	}
+	defer closeEvents()
 
-	dstMod = srcMod
+	dstMod = components[0].mod
 	if len(args) >= 2 {
 		dstMod = args[1]
 		if err := module.CheckPath(dstMod); err != nil {
-			log.Fatalf("invalid destination module name: %v", err)
+			return fmt.Errorf("invalid destination module name: %v", err)
+		}
+	} else if inferred, err := git.RemoteModulePath(ctx, "."); err == nil && module.CheckPath(inferred) == nil {
+		// Inside a repository the module path usually follows its remote,
+		// offer that instead of the template's own module path.
+		dstMod, err = promptDestination(inferred)
+		if err != nil {
+			return err
+		}
+	}
+	if policy := userSettings.Destination; policy != nil {
+		if err := policy.Allow(dstMod); err != nil {
+			return fmt.Errorf("destination module rejected by the organization policy: %v", err)
+		}
+	}
+	if len(args) < 2 && dstMod == components[0].mod {
+		// Generating a project that claims the template's own module path is
+		// rarely intended, make sure before going on. Passing dst explicitly
+		// skips the question.
+		log.Printf("WARNING: no destination module given, the project will use the template's module path %s", dstMod)
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("Use %s as the module path of the new project", dstMod),
+			IsConfirm: true,
+		}
+		if _, err := runPrompt(&prompt); err != nil {
+			return errors.New("aborted, pass the destination module path as the second argument")
 		}
 	}
 
 	var dir string
-	if len(args) == 3 {
+	switch {
+	case len(args) == 3 && targetDir != "":
+		return errors.New("target directory given both as an argument and with --dir")
+	case len(args) == 3:
 		dir = args[2]
-	} else {
+	case targetDir != "":
+		dir = targetDir
+	default:
 		dir = "." + string(filepath.Separator) + path.Base(dstMod)
 	}
+	if err := checkTargetDir(dir, baseDir); err != nil {
+		return err
+	}
+
+	// With --branch, the project is generated into a scratch copy of dir
+	// and committed to a new branch.
+	result := &summary{Module: dstMod, Dir: dir}
+	var branch *branchTarget
+	if scaffoldBranch != "" {
+		branch, err = newBranchTarget(ctx, dir)
+		if err != nil {
+			return err
+		}
+		defer branch.cleanup()
+		dir = branch.dir()
+	}
 
-	// Dir must not exist or must be an empty directory.
+	// Dir must not exist or must be an empty directory, unless only some
+	// files are generated, as into an existing project.
 	de, err := os.ReadDir(dir)
-	if err == nil && len(de) > 0 {
-		log.Fatalf("target directory %s exists and is non-empty", dir)
+	if err == nil && len(de) > 0 && len(onlyGlobs) == 0 {
+		return fmt.Errorf("target directory %s exists and is non-empty", result.Dir)
 	}
 	needMkdir := err != nil
 
-	var stdout, stderr bytes.Buffer
-	command := exec.Command("go", "mod", "download", "-json", ver)
-	command.Stdout = &stdout
-	command.Stderr = &stderr
-	if err = command.Run(); err != nil {
-		log.Fatalf("go mod download -json %s: %v\n%s%s", ver, err, stderr.Bytes(), stdout.Bytes())
+	// Answers imported from another scaffolding tool, given in values and
+	// answer files or set with --var are not prompted for again. Later
+	// sources take precedence.
+	inputs := make(map[string]string)
+	if importFile != "" {
+		inputs, err = answers.Import(importFile)
+		if err != nil {
+			return err
+		}
 	}
-
-	var info struct {
-		Dir string
+	if files := append(slices.Clone(valuesFiles), answerFiles...); len(files) > 0 {
+		loaded, err := answers.Load(files...)
+		if err != nil {
+			return err
+		}
+		for name, value := range loaded {
+			inputs[name] = value
+		}
 	}
-	if err = json.Unmarshal(stdout.Bytes(), &info); err != nil {
-		log.Fatalf("go mod download -json %s: invalid JSON output: %v\n%s%s", ver, err, stderr.Bytes(), stdout.Bytes())
+	vars, err := parseVars(varFlags)
+	if err != nil {
+		return err
+	}
+	for name, value := range vars {
+		inputs[name] = value
 	}
 
-	if needMkdir {
-		if err := os.MkdirAll(dir, 0777); err != nil {
-			log.Fatalf("mkdir error: %s", err)
+	var features map[string]bool
+
+	// With --prompt-first, answer the prompts from previously cached manifests
+	// so that a wrong template or missing answer is discovered before the download.
+	if promptFirst {
+		var cached []*project.Config
+		for _, c := range components {
+			manifest, err := cache.LoadManifest(c.mod, c.query)
+			if os.IsNotExist(err) {
+				log.Printf("no cached manifest for %s, prompting after download", c)
+				cached = nil
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := manifest.Destination.Allow(dstMod); err != nil {
+				return fmt.Errorf("destination module rejected by %s: %v", c, err)
+			}
+			cached = append(cached, manifest)
+		}
+		if cached != nil {
+			emitPhase(progress.PhasePrompt)
+			merged := mergeConfigs(cached)
+			features, err = selectFeatures(cmd, merged)
+			if err != nil {
+				return err
+			}
+			inputs, err = runPrompts(ctx, merged, merged.Migrate(inputs), features)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	// Copy from module cache into new directory, making edits as needed.
-	err = filepath.WalkDir(info.Dir, func(src string, d fs.DirEntry, err error) error {
+	// Download every component and read its manifest straight from the module
+	// cache, so prompting finishes before anything is written to the target directory.
+	emitPhase(progress.PhaseDownload)
+	index, err := loadIndex()
+	if err != nil {
+		return err
+	}
+	configs := make([]*project.Config, 0, len(components))
+	for _, c := range components {
+		// Templates blocked in the index are not even downloaded.
+		if state, _ := lifecycle(c, index); state == project.StateBlocked {
+			return checkLifecycle(c, index)
+		}
+		c.info, err = c.source.resolve(ctx, c)
 		if err != nil {
-			log.Fatal(err)
+			return err
+		}
+		if err := limits.check(ctx, c.info.Dir); err != nil {
+			return err
 		}
-		rel, err := filepath.Rel(info.Dir, src)
+
+		manifest, err := c.load(result.funcs())
 		if err != nil {
-			log.Fatal(err)
+			return err
+		}
+		if err := checkLifecycle(c, index); err != nil {
+			return err
+		}
+		if err := c.config.Destination.Allow(dstMod); err != nil {
+			return fmt.Errorf("destination module rejected by %s: %v", c, err)
+		}
+		if err := checkUnusedVariables(c); err != nil {
+			return err
+		}
+		if err := c.importVariables(ctx); err != nil {
+			return err
 		}
-		dstPath := filepath.Join(dir, rel)
-		if d.IsDir() {
-			if err := os.MkdirAll(dstPath, 0777); err != nil {
-				log.Fatal(err)
+		if c.source.cacheable() {
+			if err := cache.SaveManifest(c.mod, manifest, c.query, c.info.Version); err != nil {
+				log.Printf("caching manifest: %v", err)
 			}
-			return nil
 		}
+		configs = append(configs, c.config)
+	}
+	config = mergeConfigs(configs)
+	if modelsFile != "" {
+		models, err := project.LoadModels(modelsFile)
+		if err != nil {
+			return err
+		}
+		for _, c := range components {
+			c.config.Models = models
+		}
+		config.Models = models
+	}
+	if registerCatalog && !slices.ContainsFunc(configs, func(c *project.Config) bool { return c.Catalog != nil }) {
+		return errors.New("the template declares no catalog to register the service in")
+	}
+	emitPhase(progress.PhasePrompt)
+
+	if features == nil {
+		features, err = selectFeatures(cmd, config)
+		if err != nil {
+			return err
+		}
+	}
+	inputs, err = runPrompts(ctx, config, config.Migrate(inputs), features)
+	if err != nil {
+		return err
+	}
+	if err := config.CheckConstraints(inputs, features); err != nil {
+		return err
+	}
+	for _, c := range components {
+		if err := c.useLocale(inputs); err != nil {
+			return err
+		}
+	}
 
-		data, err := os.ReadFile(src)
+	if layoutName == "" {
+		layoutName, err = selectLayout(config)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
+	} else if config.Layout(layoutName) == nil {
+		return fmt.Errorf("template has no layout %s", layoutName)
+	}
 
-		isRoot := !strings.Contains(rel, string(filepath.Separator))
-		if strings.HasSuffix(rel, ".go") {
-			data = fixGo(data, rel, srcMod, dstMod, isRoot)
+	if !dryRun {
+		preInit := func(config *project.Config) []project.Hook { return config.Hooks.PreInit }
+		if err := runHooks(ctx, "", components, preInit, inputs, features); err != nil {
+			return err
 		}
-		if rel == "go.mod" {
-			data = fixGoMod(data, dstMod)
+	}
+	if err := runHelpers(ctx, components, inputs, features); err != nil {
+		return err
+	}
+
+	emitPhase(progress.PhasePlan)
+	files, err := planFiles(ctx, components, inputs, features, layoutName)
+	if err != nil {
+		reportTemplateError(err, inputs)
+		return err
+	}
+	if len(onlyGlobs) > 0 {
+		files, err = selectOnly(dir, files)
+		if err != nil {
+			return err
 		}
+	}
 
-		if err := os.WriteFile(dstPath, data, 0666); err != nil {
-			log.Fatal(err)
+	if dryRun {
+		changes, err := planChanges(ctx, files, inputs, features)
+		if err != nil {
+			reportTemplateError(err, inputs)
+			return err
+		}
+		if err := printChanges(cmd.OutOrStdout(), result.Dir, changes, jsonOutput); err != nil {
+			return err
 		}
 		return nil
-	})
-	if err != nil {
-		log.Fatal(err)
 	}
 
-	templateFile := filepath.Join(dir, "template.yaml")
-	err = readConfig(templateFile)
-	if err != nil {
-		log.Fatal(err)
+	if needMkdir {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return fmt.Errorf("mkdir error: %s", err)
+		}
 	}
 
-	inputs, err := runPrompts(config)
+	// Every write goes through the sandbox, confining it to dir.
+	var audit io.Writer
+	if auditLog != "" {
+		f, err := os.OpenFile(auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		audit = f
+	}
+	box, err := sandbox.New(dir, audit)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = replaceVars(dir, inputs)
+	// Copy from module cache into new directory, making edits as needed.
+	emitPhase(progress.PhaseWrite)
+	written, err := writeFiles(ctx, box, files, inputs, features)
 	if err != nil {
-		log.Fatal(err)
+		reportTemplateError(err, inputs)
+		return err
+	}
+	if err := renderReadme(result, box, components, inputs, features, written); err != nil {
+		reportTemplateError(err, inputs)
+		return err
+	}
+	if err := writeComposedReadme(box, components, inputs, features, written); err != nil {
+		reportTemplateError(err, inputs)
+		return err
+	}
+	if err := writeReleaseFiles(box, components, inputs, features, written); err != nil {
+		reportTemplateError(err, inputs)
+		return err
+	}
+	if saveAnswers && onlySelected(answers.FileName) {
+		if err := writeAnswers(box, components, inputs, features, written); err != nil {
+			return err
+		}
+	}
+	catalogEntry, err := writeCatalogInfo(box, components, inputs, features, written)
+	if err != nil {
+		reportTemplateError(err, inputs)
+		return err
+	}
+
+	emitPhase(progress.PhaseFormat)
+	runFormatters(ctx, dir, config.Formatters, written)
+	if err := tidyModule(ctx, box, written); err != nil {
+		return err
+	}
+	postInit := func(config *project.Config) []project.Hook { return config.Hooks.PostInit }
+	if err := runHooks(ctx, dir, components, postInit, inputs, features); err != nil {
+		return err
 	}
 
-	if config.DeleteTemplateFile {
-		err = os.Remove(templateFile)
+	if config.DeleteTemplateFile && written[project.FileName] {
+		err = box.Remove(project.FileName)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
+		delete(written, project.FileName)
 	}
 
-	log.Printf("initialized %s in %s", dstMod, dir)
-}
+	if err := stampTimes(box, mtimeMode, files, written); err != nil {
+		return err
+	}
+	if verifyImage != "" {
+		if err := verifyInContainer(ctx, dir, verifyImage); err != nil {
+			return err
+		}
+	}
 
-// fixGo rewrites the Go source in data to replace srcMod with dstMod.
-// isRoot indicates whether the file is in the root directory of the module,
-// in which case we also update the package name.
-func fixGo(data []byte, file string, srcMod, dstMod string, isRoot bool) []byte {
-	fileSet := token.NewFileSet()
-	f, err := parser.ParseFile(fileSet, file, data, parser.ImportsOnly)
-	if err != nil {
-		log.Fatalf("parsing source module:\n%s", err)
+	for name := range written {
+		result.Files = append(result.Files, filepath.ToSlash(name))
+	}
+	for _, c := range components {
+		if err := collectOutputs(result, c, inputs, features); err != nil {
+			return err
+		}
 	}
 
-	buf := edit.NewBuffer(data)
-	at := func(p token.Pos) int {
-		return fileSet.File(p).Offset(p)
+	if registerCatalog {
+		if err := catalogEntry.register(ctx, result); err != nil {
+			return err
+		}
 	}
 
-	srcName := path.Base(srcMod)
-	dstName := path.Base(dstMod)
-	if isRoot {
-		if name := f.Name.Name; name == srcName || name == srcName+"_test" {
-			target := dstName + strings.TrimPrefix(name, srcName)
-			if !token.IsIdentifier(target) {
-				log.Fatalf("%s: cannot rename package %s to package %s: invalid package name", file, name, target)
-			}
-			buf.Replace(at(f.Name.Pos()), at(f.Name.End()), target)
+	if !noHistory {
+		abs, err := filepath.Abs(result.Dir)
+		if err != nil {
+			abs = result.Dir
 		}
+		entry := history.Entry{Time: time.Now(), Template: templateVersions(components), Module: dstMod, Dir: abs, Answers: inputs}
+		if err := history.Add(entry); err != nil {
+			log.Printf("warning: recording history: %v", err)
+		}
+	}
+	if err := recordGeneration(components); err != nil {
+		log.Printf("warning: recording the generation in the template index: %v", err)
 	}
 
-	for _, spec := range f.Imports {
-		pathStr, err := strconv.Unquote(spec.Path.Value)
+	if branch != nil {
+		message := fmt.Sprintf("Scaffold %s\n\nGenerated by gonew from %s.", dstMod, templateVersions(components))
+		commit, err := branch.commit(ctx, message)
 		if err != nil {
-			continue
+			return err
 		}
-		if pathStr == srcMod {
-			if srcName != dstName && spec.Name == nil {
-				// Add package rename because source code uses original name.
-				// The renaming looks strange, but template authors are unlikely to
-				// create a template where the root package is imported by packages
-				// in subdirectories, and the renaming at least keeps the code working.
-				// A more sophisticated approach would be to rename the uses of
-				// the package identifier in the file too, but then you have to worry about
-				// name collisions, and given how unlikely this is, it doesn't seem worth
-				// trying to clean up the file that way.
-				buf.Insert(at(spec.Path.Pos()), srcName+" ")
+		log.Printf("committed %s to branch %s as %.12s", result.Dir, scaffoldBranch, commit)
+		if createPR {
+			if err := openPullRequest(ctx, branch, result, components, inputs, features); err != nil {
+				return err
 			}
-			// Change import path to dstMod
-			buf.Replace(at(spec.Path.Pos()), at(spec.Path.End()), strconv.Quote(dstMod))
 		}
-		if strings.HasPrefix(pathStr, srcMod+"/") {
-			// Change import path to begin with dstMod
-			buf.Replace(at(spec.Path.Pos()), at(spec.Path.End()), strconv.Quote(strings.Replace(pathStr, srcMod, dstMod, 1)))
+	}
+
+	emitPhase(progress.PhaseDone)
+	log.Printf("initialized %s in %s", dstMod, result.Dir)
+	result.Warnings = reportedWarnings()
+	if err := result.print(cmd.OutOrStdout(), jsonOutput); err != nil {
+		return err
+	}
+	return checkWarnings()
+}
+
+// promptDestination asks for the destination module path, defaulting to suggested.
+func promptDestination(suggested string) (string, error) {
+	prompt := promptui.Prompt{
+		Label:    "Destination module path",
+		Default:  suggested,
+		Validate: validate.ModulePath().Validate,
+	}
+	return runPrompt(&prompt)
+}
+
+// checkTargetDir rejects target directories that would clobber something
+// other than a new project: the filesystem root, the module cache, or a
+// parent of the current working directory. When base is set,
+// dir must also resolve inside it.
+func checkTargetDir(dir, base string) error {
+	resolved, err := safepath.Resolve(dir)
+	if err != nil {
+		return fmt.Errorf("resolving target directory %s: %v", dir, err)
+	}
+
+	if safepath.IsRoot(resolved) {
+		return fmt.Errorf("target directory %s is the filesystem root", dir)
+	}
+
+	if base != "" {
+		resolvedBase, err := safepath.Resolve(base)
+		if err != nil {
+			return fmt.Errorf("resolving base directory %s: %v", base, err)
+		}
+		if !safepath.Within(resolvedBase, resolved) {
+			return fmt.Errorf("target directory %s resolves outside of %s", dir, base)
 		}
 	}
-	return buf.Bytes()
+
+	if wd, err := os.Getwd(); err == nil {
+		if wd, err = safepath.Resolve(wd); err == nil && wd != resolved && safepath.Within(resolved, wd) {
+			return fmt.Errorf("target directory %s is a parent of the current directory", dir)
+		}
+	}
+
+	output, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return fmt.Errorf("go env GOMODCACHE: %v", err)
+	}
+	if modCache := strings.TrimSpace(string(output)); modCache != "" {
+		if modCache, err = safepath.Resolve(modCache); err == nil && safepath.Within(modCache, resolved) {
+			return fmt.Errorf("target directory %s is inside the module cache", dir)
+		}
+	}
+
+	return nil
 }
 
-// fixGoMod rewrites the go.mod content in data to replace srcMod with dstMod
-// in the module path.
-func fixGoMod(data []byte, dstMod string) []byte {
-	file, err := modfile.ParseLax("go.mod", data, nil)
+// sizeLimits bounds the resources an untrusted template may consume.
+// Zero values mean no limit.
+type sizeLimits struct {
+	MaxFiles     int
+	MaxTotalSize int64
+	MaxFileSize  int64
+}
+
+// check walks the template in dir and fails as soon as a limit is exceeded,
+// before anything has been written to the target directory.
+func (l sizeLimits) check(ctx context.Context, dir string) error {
+	if l.MaxFiles <= 0 && l.MaxTotalSize <= 0 && l.MaxFileSize <= 0 {
+		return nil
+	}
+
+	var files int
+	var total int64
+	return filepath.WalkDir(dir, func(src string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files++
+		total += fi.Size()
+		switch {
+		case l.MaxFiles > 0 && files > l.MaxFiles:
+			return fmt.Errorf("template has more than %d files", l.MaxFiles)
+		case l.MaxFileSize > 0 && fi.Size() > l.MaxFileSize:
+			return fmt.Errorf("template file %s is larger than %d bytes", src, l.MaxFileSize)
+		case l.MaxTotalSize > 0 && total > l.MaxTotalSize:
+			return fmt.Errorf("template is larger than %d bytes", l.MaxTotalSize)
+		}
+		return nil
+	})
+}
+
+// templateRoot returns the directory holding the files to generate: the
+// manifest's root directory if it names one, otherwise the module root. For
+// cookiecutter templates this is the single top-level directory whose name
+// is templated, otherwise it is the template directory itself.
+func templateRoot(dir string, config *project.Config) (string, error) {
+	if config.Root != "" {
+		root := filepath.Join(dir, filepath.FromSlash(config.Root))
+		fi, err := os.Stat(root)
+		if err != nil {
+			return "", fmt.Errorf("%s: root: %v", project.FileName, err)
+		}
+		if !fi.IsDir() {
+			return "", fmt.Errorf("%s: root %s is not a directory", project.FileName, config.Root)
+		}
+		return root, nil
+	}
+	if !config.Cookiecutter {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		log.Fatalf("parsing source module:\n%s", err)
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.Contains(entry.Name(), "{{") {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("cookiecutter template has no templated project directory")
+}
+
+// moduleInfo is the subset of the go mod download -json output used by gonew.
+type moduleInfo struct {
+	Dir     string
+	Version string
+	Error   string
+}
+
+// rootModule returns the module path the sources under the component's root
+// import their own packages with. A root keeping its own go.mod declares it
+// there; otherwise the root is a directory of the template module, such as
+// example.com/tpl/skeleton, since the go command leaves nested modules out of
+// module zips.
+func rootModule(c *component) (string, error) {
+	if c.config.Root == "" {
+		return c.mod, nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.root, "go.mod"))
+	if os.IsNotExist(err) {
+		return path.Join(c.mod, c.config.Root), nil
 	}
-	err = file.AddModuleStmt(dstMod)
 	if err != nil {
-		log.Fatalf("add module stmt:\n%s", err)
+		return "", err
+	}
+	if mod := modfile.ModulePath(data); mod != "" {
+		return mod, nil
+	}
+	return c.mod, nil
+}
+
+// downloadModule downloads the module query ver (path@version) into the
+// module cache and reports where it was extracted. When the proxies of
+// GOPROXY fail, each of them is tried in turn to report what went wrong.
+func downloadModule(ctx context.Context, ver string) (*moduleInfo, error) {
+	info, err := goModDownload(ctx, ver)
+	if err == nil {
+		return info, nil
+	}
+	return downloadFallback(ctx, ver, err)
+}
+
+// goModDownload runs go mod download for ver with the template fetching
+// settings and the extra environment env.
+func goModDownload(ctx context.Context, ver string, env ...string) (*moduleInfo, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "go", "mod", "download", "-json", ver)
+	command.Env = append(append(os.Environ(), fetchEnv()...), env...)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	runErr := command.Run()
+
+	info := &moduleInfo{}
+	if err := json.Unmarshal(stdout.Bytes(), info); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("go mod download -json %s: %v\n%s%s", ver, runErr, stderr.Bytes(), stdout.Bytes())
+		}
+		return nil, fmt.Errorf("go mod download -json %s: invalid JSON output: %v\n%s%s", ver, err, stderr.Bytes(), stdout.Bytes())
+	}
+	if info.Error != "" {
+		return nil, errors.New(info.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("go mod download -json %s: %v\n%s", ver, runErr, stderr.Bytes())
 	}
-	format, err := file.Format()
+	return info, nil
+}
+
+// fixGo rewrites the Go source in data to replace srcMod with dstMod. Files
+// in the root directory of the module also get their package renamed.
+// Imports of packages that layout moves are rewritten to their new location.
+// A file that cannot be rewritten, such as one that does not parse, is kept
+// as is with a warning rather than failing the whole project.
+func fixGo(data []byte, file string, srcMod, dstMod string, layout *project.Layout) []byte {
+	fixed, err := rewrite.GoFile(file, data, rewriteOptions(srcMod, dstMod, layout))
 	if err != nil {
+		warn(warnImportNotRewritten, file, "copied without rewriting the module path:\n%s", err)
 		return data
 	}
-	return format
+	return fixed
 }
 
-// readConfig Reading YAML configuration files
-func readConfig(filename string) error {
-	data, err := os.ReadFile(filename)
+// fixConfig rewrites the module path in data if file is a config file known
+// to embed it, such as .golangci.yml or sqlc.yaml.
+func fixConfig(data []byte, file string, srcMod, dstMod string, layout *project.Layout) ([]byte, error) {
+	data, err := rewrite.Config(file, data, rewriteOptions(srcMod, dstMod, layout))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%s: %v", file, err)
 	}
+	return data, nil
+}
+
+// rewriteOptions returns the options moving the packages of srcMod to dstMod,
+// at the locations of layout.
+func rewriteOptions(srcMod, dstMod string, layout *project.Layout) rewrite.Options {
+	return rewrite.Options{
+		From:              srcMod,
+		To:                dstMod,
+		RenameRootPackage: true,
+		IncludeTests:      true,
+		MapPackage: func(dir string) string {
+			return strings.TrimSuffix(layout.Map(dir+"/"), "/")
+		},
+	}
+}
 
-	err = yaml.Unmarshal(data, &config)
-	return err
+// fixGoMod rewrites the go.mod content in data to replace srcMod with dstMod
+// in the module path. A go.mod that does not parse is kept as is with a
+// warning, like Go files are.
+func fixGoMod(data []byte, dstMod string) []byte {
+	fixed, err := rewrite.GoMod(data, dstMod)
+	if err != nil {
+		warn(warnImportNotRewritten, "go.mod", "copied without rewriting the module path:\n%s", err)
+		return data
+	}
+	return fixed
 }
 
-// runPrompts Run interactive prompts based on configuration
-func runPrompts(config *project.Config) (map[string]string, error) {
-	answers := make(map[string]string)
+// runPrompts Run interactive prompts based on configuration,
+// skipping variables that already have an answer
+func runPrompts(ctx context.Context, config *project.Config, answers map[string]string, features map[string]bool) (map[string]string, error) {
+	if answers == nil {
+		answers = make(map[string]string)
+	}
 
+	var pending, given []project.Variable
+	var unset []string
 	for _, variable := range config.Variables {
-		prompt := promptui.Prompt{
-			Label: variable.Placeholder,
-			Validate: func(input string) error {
-				if len(input) == 0 {
-					return fmt.Errorf(variable.Placeholder)
+		if answer, ok := answers[variable.Name]; ok {
+			// Answers given in files or flags, empty ones included, follow
+			// the same rules as prompted ones. Variables that are not asked
+			// are answered with "", as below.
+			if answer == "" {
+				if len(pending) > 0 {
+					// Whether it is asked is known once the form is
+					// submitted.
+					given = append(given, variable)
+					continue
+				}
+				if asked, err := variable.Asked(config.Vars(answers, features)); err != nil {
+					return nil, err
+				} else if !asked {
+					continue
 				}
-				return nil
-			},
+			}
+			if err := checkAnswer(ctx, variable, answer); err != nil {
+				return nil, fmt.Errorf("answer to %s: %v", variable.Name, err)
+			}
+			continue
+		}
+		// The form decides whether its variables are asked, as their
+		// conditions may refer to the answers given in it.
+		if formMode && !noPrompt {
+			pending = append(pending, variable)
+			continue
+		}
+		if asked, err := variable.Asked(config.Vars(answers, features)); err != nil {
+			return nil, err
+		} else if !asked {
+			answers[variable.Name] = ""
+			continue
+		}
+		if variable.Generated != "" {
+			value, err := render.Generate(variable.Name, variable.Generated)
+			if err != nil {
+				return nil, err
+			}
+			answers[variable.Name] = value
+			continue
 		}
 
-		name, err := prompt.Run()
+		rule, err := variable.Rule()
 		if err != nil {
 			return nil, err
 		}
+		value, err := defaultValue(ctx, config, variable, answers, features)
+		if err != nil {
+			return nil, err
+		}
+
+		// Without prompts the default is the answer, and variables without
+		// one are collected to be reported together.
+		if noPrompt {
+			if value == "" && variable.IsRequired() {
+				unset = append(unset, variable.Name)
+				continue
+			}
+			if err := checkAnswer(ctx, variable, value); err != nil {
+				return nil, fmt.Errorf("%s: default %q: %v", variable.Name, value, err)
+			}
+			answers[variable.Name] = value
+			continue
+		}
+
+		emit(progress.Event{Kind: progress.KindPrompt, Time: time.Now(), Variable: variable.Name})
+		var name string
+		for {
+			name, err = promptVariable(variable, variable.Placeholder, value, rule)
+			if err != nil {
+				return nil, err
+			}
+			err = checkRemote(ctx, variable, name)
+			if err == nil {
+				break
+			}
+			log.Printf("%s: %v", variable.Placeholder, err)
+		}
 		answers[variable.Name] = name
 	}
 
+	if len(unset) > 0 {
+		return nil, fmt.Errorf("no value for variables %s; set them with --var name=value or --answers", strings.Join(unset, ", "))
+	}
+	if len(pending) == 0 {
+		return answers, nil
+	}
+	answers, err := runForm(ctx, config, pending, answers, features)
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range given {
+		if asked, err := variable.Asked(config.Vars(answers, features)); err != nil {
+			return nil, err
+		} else if !asked {
+			continue
+		}
+		if err := checkAnswer(ctx, variable, ""); err != nil {
+			return nil, fmt.Errorf("answer to %s: %v", variable.Name, err)
+		}
+	}
 	return answers, nil
 }
 
-func replaceVars(dir string, inputs map[string]string) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// checkAnswer runs the checks every answer to variable goes through, whether
+// prompted for, defaulted or given in files and flags: its required, type
+// and validate rules, then its validate_remote endpoint.
+func checkAnswer(ctx context.Context, variable project.Variable, value string) error {
+	rule, err := variable.Rule()
+	if err != nil {
+		return err
+	}
+	if err := rule.Validate(value); err != nil {
+		return err
+	}
+	return checkRemote(ctx, variable, value)
+}
+
+// variableHelp returns the help of variable followed by its example, or ""
+// if it has neither.
+func variableHelp(variable project.Variable) string {
+	var lines []string
+	if help := strings.TrimSpace(variable.Help); help != "" {
+		lines = append(lines, help)
+	}
+	if variable.Example != "" {
+		lines = append(lines, "Example: "+variable.Example)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// promptVariable asks for the answer to variable, offering value: a choice
+// among the options of select variables, a yes or no question for booleans
+// and a line of text validated by rule otherwise, which may be left empty
+// for optional variables. Answering "?" shows the help of the variable.
+func promptVariable(variable project.Variable, label, value string, rule validate.Rule) (string, error) {
+	help := variableHelp(variable)
+	switch variable.Type {
+	case "select":
+		cursor := max(slices.Index(variable.Options, value), 0)
+		prompt := promptui.Select{
+			Label:     label,
+			Items:     variable.Options,
+			Size:      len(variable.Options),
+			CursorPos: cursor,
+		}
+		withSelectHelp(&prompt, help)
+		i, err := runSelect(&prompt, cursor)
 		if err != nil {
-			return err
+			return "", err
 		}
-		if !info.IsDir() {
-			relPath, err := filepath.Rel(dir, path)
-			if err != nil {
-				return err
+		return variable.Options[i], nil
+	case "bool":
+		def, _ := strconv.ParseBool(value)
+		yes, err := runConfirm(label, def, help)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(yes), nil
+	}
+
+	prompt := promptui.Prompt{
+		Label:   label,
+		Default: value,
+		// The default of an optional variable can be erased.
+		AllowEdit: !variable.IsRequired(),
+		Validate: func(input string) error {
+			if err := rule.Validate(input); err != nil {
+				return fmt.Errorf("%s: %v", label, err)
+			}
+			return nil
+		},
+	}
+	withHelp(&prompt, help)
+	answer, err := runPrompt(&prompt)
+	if errors.Is(err, errPromptTimeout) && !variable.IsRequired() {
+		return "", nil
+	}
+	return answer, err
+}
+
+// parseVars parses --var flags of the form name=value.
+func parseVars(flags []string) (map[string]string, error) {
+	vars := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q, want name=value", flag)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// defaultValue returns the value offered for variable given the answers so far.
+func defaultValue(ctx context.Context, config *project.Config, variable project.Variable, answers map[string]string, features map[string]bool) (string, error) {
+	// Defaults may refer to earlier answers, as cookiecutter defaults do.
+	value := variable.Default
+	if variable.From != "" {
+		if suggested, err := cloud.Lookup(ctx, variable.From); err == nil {
+			value = suggested
+		}
+	}
+	if left, _ := config.Delims(); strings.Contains(value, left) {
+		engine, err := render.New(config.Engine, nil, renderOptions(config))
+		if err != nil {
+			return "", err
+		}
+		rendered, err := engine.Render(variable.Name, value, config.Context(answers, features))
+		if err != nil {
+			return "", err
+		}
+		value = string(rendered)
+	}
+	if variable.Allocate {
+		return allocatePort(config, variable, value, answers)
+	}
+	return value, nil
+}
+
+// allocatePort returns a free local port for variable, preferring value,
+// that no other port variable of config was given.
+func allocatePort(config *project.Config, variable project.Variable, value string, answers map[string]string) (string, error) {
+	preferred, _ := strconv.Atoi(value)
+	taken := make(map[int]bool)
+	for _, other := range config.Variables {
+		if other.Type != "port" || other.Name == variable.Name {
+			continue
+		}
+		if port, err := strconv.Atoi(answers[other.Name]); err == nil {
+			taken[port] = true
+		}
+	}
+	port, err := ports.Free(preferred, taken)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", variable.Name, err)
+	}
+	if preferred > 0 && port != preferred {
+		log.Printf("%s: port %d is in use, offering %d", variable.Name, preferred, port)
+	}
+	return strconv.Itoa(port), nil
+}
+
+// selectFeatures returns the selected features of the template, taken from
+// --feature when given and otherwise chosen from a checklist.
+func selectFeatures(cmd *cobra.Command, config *project.Config) (map[string]bool, error) {
+	selected := make(map[string]bool, len(config.Features))
+	if cmd.Flags().Changed("feature") {
+		for _, name := range featureList {
+			found := false
+			for _, feature := range config.Features {
+				found = found || feature.Name == name
+			}
+			if !found {
+				return nil, fmt.Errorf("template has no feature %s", name)
+			}
+			selected[name] = true
+		}
+		return selected, nil
+	}
+
+	if len(config.Features) == 0 {
+		return selected, nil
+	}
+	for _, feature := range config.Features {
+		selected[feature.Name] = feature.Default
+	}
+
+	// promptui has no multi-select, so the checklist is a select whose
+	// items toggle until the last item is chosen.
+	cursor := 0
+	for {
+		items := make([]string, 0, len(config.Features)+1)
+		for _, feature := range config.Features {
+			mark := "[ ]"
+			if selected[feature.Name] {
+				mark = "[x]"
+			}
+			item := fmt.Sprintf("%s %s", mark, feature.Name)
+			if feature.Description != "" {
+				item += " - " + feature.Description
 			}
-			content, err := os.ReadFile(path)
+			items = append(items, item)
+		}
+		items = append(items, "Done")
+
+		prompt := promptui.Select{
+			Label:     "Select features",
+			Items:     items,
+			Size:      len(items),
+			CursorPos: cursor,
+		}
+		// Without input the current selection is accepted.
+		i, err := runSelect(&prompt, len(config.Features))
+		if err != nil {
+			return nil, err
+		}
+		if i == len(config.Features) {
+			return selected, nil
+		}
+		name := config.Features[i].Name
+		selected[name] = !selected[name]
+		cursor = i
+	}
+}
+
+// selectLayout asks which layout to generate when the template offers
+// several, the first being the default.
+func selectLayout(config *project.Config) (string, error) {
+	switch len(config.Layouts) {
+	case 0:
+		return "", nil
+	case 1:
+		return config.Layouts[0].Name, nil
+	}
+
+	items := make([]string, len(config.Layouts))
+	for i, layout := range config.Layouts {
+		items[i] = layout.Name
+		if layout.Description != "" {
+			items[i] += " - " + layout.Description
+		}
+	}
+	prompt := promptui.Select{
+		Label: "Select layout",
+		Items: items,
+	}
+	i, err := runSelect(&prompt, 0)
+	if err != nil {
+		return "", err
+	}
+	return config.Layouts[i].Name, nil
+}
+
+// writeFiles generates the planned files into box, merging the files several
+// components provide, and returns the set of paths written.
+func writeFiles(ctx context.Context, box *sandbox.Sandbox, files []*plannedFile, inputs map[string]string, features map[string]bool) (map[string]bool, error) {
+	written := make(map[string]bool)
+	for i, file := range files {
+		// Rendering a file cannot be interrupted, stop between files.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := generateFile(ctx, file, inputs, features)
+		if err != nil {
+			return nil, err
+		}
+		if written[file.dstRel] {
+			data, err = mergeFile(box, file.dstRel, data)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("merging %s: %v", file.dstRel, err)
 			}
+		}
 
-			return generateFile(inputs, relPath, string(content), dir)
+		perm := os.FileMode(0666)
+		if file.executable {
+			perm = 0777
 		}
-		return nil
-	})
+		if err := box.WriteFile(file.dstRel, data, perm); err != nil {
+			return nil, err
+		}
+		written[file.dstRel] = true
+		emit(progress.Event{Kind: progress.KindFile, Time: time.Now(), Path: filepath.ToSlash(file.dstRel), Index: i + 1, Total: len(files)})
+	}
+	return written, nil
+}
+
+// onlySelected reports whether the file at the slash-separated path rel is
+// generated with the --only globs.
+func onlySelected(rel string) bool {
+	return len(onlyGlobs) == 0 || glob.MatchAny(onlyGlobs, rel)
+}
+
+// selectOnly keeps the planned files selected by --only, which may not
+// overwrite files already in dir.
+func selectOnly(dir string, files []*plannedFile) ([]*plannedFile, error) {
+	var selected []*plannedFile
+	var existing []string
+	for _, file := range files {
+		rel := filepath.ToSlash(file.dstRel)
+		if !onlySelected(rel) {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, file.dstRel)); err == nil {
+			existing = append(existing, rel)
+		}
+		selected = append(selected, file)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no file of the template matches --only %s", strings.Join(onlyGlobs, ","))
+	}
+	if len(existing) > 0 {
+		slices.Sort(existing)
+		existing = slices.Compact(existing)
+		return nil, fmt.Errorf("refusing to overwrite existing files in %s:\n  %s", dir, strings.Join(existing, "\n  "))
+	}
+	return selected, nil
+}
+
+// writeAnswers writes the answers file into the project. Only the answers to
+// the template's variables are kept, without secret and generated ones.
+func writeAnswers(box *sandbox.Sandbox, components []*component, inputs map[string]string, features map[string]bool, written map[string]bool) error {
+	if written[answers.FileName] {
+		return fmt.Errorf("%s is generated by the template", answers.FileName)
+	}
+
+	record := &answers.Record{Template: templateVersions(components), Layout: layoutName, Answers: make(map[string]string)}
+	for _, variable := range config.Variables {
+		if value, ok := inputs[variable.Name]; ok && !variable.Secret && variable.Generated == "" {
+			record.Answers[variable.Name] = value
+		}
+	}
+	for _, feature := range config.Features {
+		if features[feature.Name] {
+			record.Features = append(record.Features, feature.Name)
+		}
+	}
+
+	data, err := answers.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := box.WriteFile(answers.FileName, data, 0666); err != nil {
+		return err
+	}
+	written[answers.FileName] = true
+	return nil
+}
+
+// templateVersions returns the templates applied with their versions, as in
+// "example.com/base@v1.2.0+example.com/grpc@v0.3.1".
+func templateVersions(components []*component) string {
+	templates := make([]string, len(components))
+	for i, c := range components {
+		templates[i] = c.mod + "@" + c.info.Version
+	}
+	return strings.Join(templates, "+")
 }
 
-// generateFile creates a single file from a template
-func generateFile(data map[string]string, fileName, content, projectDir string) error {
-	// Parse the template
-	tmpl, err := template.New(fileName).Parse(content)
+// execMarker, as the first line of a template file, makes the generated file executable.
+const execMarker = "#!gonew:exec"
+
+// generateFile produces the content of a planned file: Go sources, go.mod and
+// known config files are rewritten for the destination module, then the
+// result is rendered, unless the template's pipeline renders first.
+func generateFile(ctx context.Context, file *plannedFile, inputs map[string]string, features map[string]bool) ([]byte, error) {
+	c := file.component
+	root, err := safepath.Resolve(c.root)
+	if err != nil {
+		return nil, err
+	}
+	if err := safepath.CheckRegular(root, file.src); err != nil {
+		return nil, fmt.Errorf("refusing to read template file: %v", err)
+	}
+	data, err := os.ReadFile(file.src)
 	if err != nil {
-		return fmt.Errorf("error parsing template %s: %v", fileName, err)
+		return nil, err
 	}
 
-	// Create the output file
-	filePath := filepath.Join(projectDir, fileName)
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return fmt.Errorf("error creating directories for %s: %v", fileName, err)
+	// The exec marker line only marks the file, it is not generated.
+	if rest, ok := bytes.CutPrefix(data, []byte(execMarker)); ok && (len(rest) == 0 || rest[0] == '\n' || rest[0] == '\r') {
+		data = bytes.TrimPrefix(bytes.TrimPrefix(rest, []byte("\r")), []byte("\n"))
+		file.executable = true
 	}
 
-	file, err := os.Create(filePath)
+	// Binary files are copied as they are.
+	if normalize.IsBinaryFile(file.rel, data) {
+		return data, nil
+	}
+
+	data, err = rewriteModule(file, data, false)
 	if err != nil {
-		return fmt.Errorf("error creating file %s: %v", fileName, err)
+		return nil, err
 	}
 
-	defer func(file *os.File) {
-		err = file.Close()
+	tmplData := file.item.context(c.context(inputs, features))
+	if !glob.MatchAny(c.config.RenderExclude, filepath.ToSlash(file.rel)) {
+		data, err = c.engine.Render(file.dstRel, string(data), tmplData)
 		if err != nil {
-			log.Fatal(err)
+			return nil, &templateError{component: c, file: filepath.ToSlash(file.rel), err: err}
 		}
-	}(file)
+	}
+	data, err = rewriteModule(file, data, true)
+	if err != nil {
+		return nil, err
+	}
+	if !c.config.Cookiecutter && c.rootMod != dstMod && strings.HasSuffix(file.dstRel, ".go") {
+		checkImports(file.dstRel, data, c.rootMod)
+	}
 
-	// Execute the template and write to file
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("error executing template %s: %v", fileName, err)
+	if banner, err := fileHeader(c, file.dstRel, tmplData); err != nil {
+		return nil, err
+	} else if banner != "" {
+		data = header.Insert(data, banner)
 	}
+	for _, processor := range c.config.PostProcess {
+		if !glob.Match(processor.Glob, filepath.ToSlash(file.dstRel)) {
+			continue
+		}
+		data, err = postProcess(ctx, processor.Command, file.dstRel, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data = normalizeText(data, file.dstRel, c.config.Normalize)
+	if c.editorConfig != nil && !normalize.IsBinary(data) {
+		data = c.editorConfig.Apply(filepath.ToSlash(file.dstRel), data)
+	}
+	return data, nil
+}
+
+// rewriteModule rewrites the template's module path in data, the content of
+// file before rendering, or after it when rendered is set. By default files
+// are rewritten before they are rendered. The render_first pipeline rewrites
+// them after, as does go_rewrite.after_render for the Go files it lists,
+// while go_rewrite.skip leaves Go files as they are.
+func rewriteModule(file *plannedFile, data []byte, rendered bool) ([]byte, error) {
+	c := file.component
+	// Cookiecutter templates carry their module path as a variable,
+	// so there is no source module path to rewrite.
+	if c.config.Cookiecutter {
+		return data, nil
+	}
+
+	renderFirst := c.config.Pipeline == project.PipelineRenderFirst
+	rel := filepath.ToSlash(file.rel)
+	if strings.HasSuffix(rel, ".go") && !glob.MatchAny(c.config.GoRewrite.Skip, rel) {
+		afterRender := renderFirst || glob.MatchAny(c.config.GoRewrite.AfterRender, rel)
+		if afterRender == rendered {
+			data = fixGo(data, file.rel, c.rootMod, dstMod, file.layout)
+		}
+	}
+	if renderFirst != rendered {
+		return data, nil
+	}
+	if rel == "go.mod" {
+		data = fixGoMod(data, dstMod)
+	}
+	return fixConfig(data, file.dstRel, c.rootMod, dstMod, file.layout)
+}
 
+// postProcess pipes data through command and returns its output.
+func postProcess(ctx context.Context, command, name string, data []byte) ([]byte, error) {
+	args := strings.Fields(command)
+	var stdout, stderr bytes.Buffer
+	process := exec.CommandContext(ctx, args[0], args[1:]...)
+	process.Stdin = bytes.NewReader(data)
+	process.Stdout = &stdout
+	process.Stderr = &stderr
+	if err := process.Run(); err != nil {
+		return nil, fmt.Errorf("post processing %s with %s: %v\n%s", name, command, err, stderr.Bytes())
+	}
+	return stdout.Bytes(), nil
+}
+
+// runFormatters runs the template's formatters on the matching generated
+// files. Formatters are optional polish: a missing or failing one is
+// reported without failing the generation.
+func runFormatters(ctx context.Context, dir string, formatters []project.Formatter, written map[string]bool) {
+	for _, formatter := range formatters {
+		var files []string
+		for name := range written {
+			if glob.Match(formatter.Glob, filepath.ToSlash(name)) {
+				files = append(files, name)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		sort.Strings(files)
+
+		args := strings.Fields(formatter.Command)
+		if _, err := exec.LookPath(args[0]); err != nil {
+			log.Printf("warning: formatter %s not found, skipping %s", args[0], formatter.Glob)
+			continue
+		}
+		command := exec.CommandContext(ctx, args[0], append(args[1:], files...)...)
+		command.Dir = dir
+		output := io.MultiWriter(os.Stderr, &outputEvents{})
+		command.Stdout = output
+		command.Stderr = output
+		if err := command.Run(); err != nil {
+			log.Printf("warning: formatter %s: %v", formatter.Command, err)
+		}
+	}
+}
+
+// Modes of --mtime.
+const (
+	mtimeNow    = "now"
+	mtimeSource = "source"
+	mtimeEpoch  = "epoch"
+)
+
+// stampTimes sets the modification times of the written files, and of the
+// directories holding them up to the project directory, so that archiving
+// the project twice gives the same bytes. In source mode a file takes the
+// time of its template file, files generated from the answers alone, such
+// as the README or go.sum, the newest time of the template files, and merged
+// files and directories the newest time of what they hold.
+func stampTimes(box *sandbox.Sandbox, mode string, files []*plannedFile, written map[string]bool) error {
+	if mode == mtimeNow {
+		return nil
+	}
+
+	epoch := time.Unix(0, 0).UTC()
+	if value := os.Getenv("SOURCE_DATE_EPOCH"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid SOURCE_DATE_EPOCH: %v", err)
+		}
+		epoch = time.Unix(seconds, 0).UTC()
+	}
+
+	sources := make(map[string]time.Time)
+	newest := epoch
+	if mode == mtimeSource {
+		for _, file := range files {
+			if !written[file.dstRel] {
+				continue
+			}
+			fi, err := os.Stat(file.src)
+			if err != nil {
+				return err
+			}
+			mtime := fi.ModTime()
+			if mtime.After(sources[file.dstRel]) {
+				sources[file.dstRel] = mtime
+			}
+			if newest == epoch || mtime.After(newest) {
+				newest = mtime
+			}
+		}
+	}
+
+	times := make(map[string]time.Time)
+	for rel := range written {
+		mtime := epoch
+		if mode == mtimeSource {
+			mtime = newest
+			if source, ok := sources[rel]; ok {
+				mtime = source
+			}
+		}
+		for name := rel; ; name = filepath.Dir(name) {
+			if mtime.After(times[name]) || times[name].IsZero() {
+				times[name] = mtime
+			}
+			if name == "." {
+				break
+			}
+		}
+	}
+
+	for name, mtime := range times {
+		// Files removed by post_init hooks have no time to set.
+		if err := box.Chtimes(name, mtime); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
 	return nil
 }
+
+// fileHeader returns the rendered header banner for the generated file name,
+// or "" if the component's header does not apply to it.
+func fileHeader(c *component, name string, tmplData map[string]any) (string, error) {
+	h := c.config.Header
+	slashed := filepath.ToSlash(name)
+	if h.Text == "" || glob.MatchAny(h.Exclude, slashed) {
+		return "", nil
+	}
+	if len(h.Include) > 0 && !glob.MatchAny(h.Include, slashed) {
+		return "", nil
+	}
+	prefix := header.Prefix(slashed, h.Comments)
+	if prefix == "" {
+		return "", nil
+	}
+
+	text, err := c.engine.Render(name+" header", h.Text, tmplData)
+	if err != nil {
+		return "", err
+	}
+	return header.Comment(string(text), prefix), nil
+}
+
+// normalizeText applies the template's normalization options, overridden by
+// the command line flags, to a rendered text file and warns about mixed line
+// endings left in place.
+func normalizeText(data []byte, name string, options project.Normalize) []byte {
+	if normalize.IsBinary(data) {
+		return data
+	}
+	if lineEndings != "" {
+		options.LineEndings = lineEndings
+	}
+	if stripBOM || options.StripBOM {
+		data = normalize.StripBOM(data)
+	}
+	if options.SquashBlankLines {
+		data = normalize.SquashBlankLines(data)
+	}
+	if options.FinalNewline {
+		data = normalize.FinalNewline(data)
+	}
+	if options.LineEndings != "" {
+		return normalize.LineEndings(data, options.LineEndings)
+	}
+	if normalize.MixedLineEndings(data) {
+		log.Printf("warning: %s has mixed line endings", name)
+	}
+	return data
+}
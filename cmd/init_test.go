@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFixGoModInvalid(t *testing.T) {
+	data := []byte("module {{ .Module }\n")
+	if got := fixGoMod(data, "example.com/new"); string(got) != string(data) {
+		t.Errorf("fixGoMod of an invalid go.mod = %q, want it unchanged", got)
+	}
+	if got := fixGoMod([]byte("module example.com/old\n"), "example.com/new"); string(got) != "module example.com/new\n" {
+		t.Errorf("fixGoMod = %q, want the module path rewritten", got)
+	}
+}
+
+func TestFixGoInvalid(t *testing.T) {
+	data := []byte("package {{ .Name }}\n\nimport \"example.com/old/a\"\n")
+	if got := fixGo(data, "main.go", "example.com/old", "example.com/new", nil); string(got) != string(data) {
+		t.Errorf("fixGo of an invalid Go file = %q, want it unchanged", got)
+	}
+}
+
+func TestInitProjectReturnsErrors(t *testing.T) {
+	defer func(v string) { mtimeMode = v }(mtimeMode)
+	mtimeMode = "yesterday"
+	c := testComponent(t, map[string]string{"go.mod": "module example.com/service\n"})
+	initCmd.SetContext(t.Context())
+	if err := initProject(initCmd, []string{c.root, "example.com/app"}); err == nil {
+		t.Error("initProject accepted an invalid --mtime")
+	}
+}
+
+func TestStampTimesReproducible(t *testing.T) {
+	defer func(mode string, prompt, history, save bool) {
+		mtimeMode, noPrompt, noHistory, saveAnswers = mode, prompt, history, save
+	}(mtimeMode, noPrompt, noHistory, saveAnswers)
+	mtimeMode, noPrompt, noHistory, saveAnswers = mtimeEpoch, true, true, true
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	c := testComponent(t, map[string]string{
+		"go.mod":              "module example.com/template\n\ngo 1.22\n",
+		"cmd/server/main.go":  "// {{ .Name }}\npackage main\n\nfunc main() {}\n",
+		"template.yaml":       "variables:\n  - name: Name\n    default: app\n",
+		"internal/db/conn.go": "package db\n",
+	})
+	archive := func(dir string) []byte {
+		if err := initProject(initCmd, []string{c.root, "example.com/app", dir}); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			hdr, err := tar.FileInfoHeader(fi, "")
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(dir, path)
+			if !fi.ModTime().Equal(time.Unix(1700000000, 0)) {
+				t.Errorf("%s: modified at %v, want $SOURCE_DATE_EPOCH", rel, fi.ModTime())
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			// PAX keeps modification times to the nanosecond, and the
+			// access and change times are the file system's.
+			hdr.Format = tar.FormatPAX
+			hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				tw.Write(data)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tw.Close()
+		return buf.Bytes()
+	}
+	initCmd.SetContext(t.Context())
+	first := archive(filepath.Join(t.TempDir(), "app"))
+	second := archive(filepath.Join(t.TempDir(), "app"))
+	if !bytes.Equal(first, second) {
+		t.Error("archives of two generations differ")
+	}
+}
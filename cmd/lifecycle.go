@@ -0,0 +1,101 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/registry"
+)
+
+var (
+	allowExperimental bool
+	initIndex         string
+)
+
+func init() {
+	initCmd.Flags().BoolVar(&allowExperimental, "allow-experimental", false, "Generate from templates in the experimental state")
+	initCmd.Flags().StringVar(&initIndex, "index", os.Getenv("GONEW_INDEX"), "Template index file or URL whose lifecycle states override those of the templates, defaults to $GONEW_INDEX or the configured index")
+}
+
+// loadIndex reads the template index of --index, if any, a file or an
+// http(s) URL. An index that cannot be read is an error rather than an empty
+// index, which would enforce none of its lifecycle states.
+func loadIndex() (*registry.Index, error) {
+	source := indexFile(initIndex)
+	if source == "" {
+		return &registry.Index{}, nil
+	}
+	index, err := registry.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("template index: %v", err)
+	}
+	return index, nil
+}
+
+// indexFile returns the template index, a file or an http(s) URL, named by
+// an --index flag, or else by the configuration file.
+func indexFile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return userSettings.Index
+}
+
+// lifecycle returns the lifecycle state of the component and the template
+// replacing it. A state recorded in the index overrides the manifest's.
+func lifecycle(c *component, index *registry.Index) (state, replacement string) {
+	if c.config != nil {
+		state, replacement = c.config.State, c.config.Replacement
+	}
+	if entry := index.Lookup(c.mod); entry != nil && entry.State != "" {
+		state = entry.State
+		if entry.Replacement != "" {
+			replacement = entry.Replacement
+		}
+	}
+	return state, replacement
+}
+
+// checkLifecycle refuses blocked templates, and experimental ones unless
+// allowed, and warns about deprecated ones.
+func checkLifecycle(c *component, index *registry.Index) error {
+	state, replacement := lifecycle(c, index)
+	instead := ""
+	if replacement != "" {
+		instead = fmt.Sprintf(", use %s instead", replacement)
+	}
+	switch state {
+	case project.StateBlocked:
+		return fmt.Errorf("template %s is blocked%s", c.mod, instead)
+	case project.StateExperimental:
+		if !allowExperimental {
+			return fmt.Errorf("template %s is experimental, generate it with --allow-experimental", c.mod)
+		}
+	case project.StateDeprecated:
+		log.Printf("warning: template %s is deprecated%s", c.mod, instead)
+	}
+	return nil
+}
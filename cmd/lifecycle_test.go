@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/betterde/gonew/internal/settings"
+)
+
+const lifecycleIndex = `templates:
+  - name: service
+    module: example.com/tpl/service
+    latest: v1.1.0
+    state: blocked
+    replacement: example.com/tpl/service/v2
+    versions:
+      - version: v1.1.0
+        published: 2026-01-02T00:00:00Z
+        notes: Adds metrics
+`
+
+func TestLifecycleURLIndex(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodGet {
+			t.Errorf("%s %s, want only GET requests", r.Method, r.URL)
+		}
+		w.Write([]byte(lifecycleIndex))
+	}))
+	defer srv.Close()
+
+	defer func(index string, s *settings.Settings) { initIndex, userSettings = index, s }(initIndex, userSettings)
+	initIndex = ""
+	userSettings = &settings.Settings{Index: srv.URL + "/index.yaml", Analytics: true}
+
+	index, err := loadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &component{mod: "example.com/tpl/service", info: &moduleInfo{Version: "v1.1.0"}}
+	if err := checkLifecycle(c, index); err == nil || !strings.Contains(err.Error(), "example.com/tpl/service/v2") {
+		t.Errorf("checkLifecycle = %v, want the template blocked with its replacement", err)
+	}
+
+	// Generations are not recorded in indexes served over HTTP.
+	before := requests
+	if err := recordGeneration([]*component{c}); err != nil || requests != before {
+		t.Errorf("recordGeneration = %v after %d requests, want nil and no request", err, requests-before)
+	}
+
+	defer func(index string) { versionsIndex = index }(versionsIndex)
+	versionsIndex = ""
+	var out bytes.Buffer
+	versionsCmd.SetOut(&out)
+	defer versionsCmd.SetOut(nil)
+	listVersions(versionsCmd, []string{"example.com/tpl/service"})
+	if want := "v1.1.0 (2026-01-02)\n  Adds metrics\n"; out.String() != want {
+		t.Errorf("versions = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLoadIndexUnreadable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	defer func(index string) { initIndex = index }(initIndex)
+	for _, source := range []string{srv.URL + "/index.yaml", filepath.Join(t.TempDir(), "missing.yaml")} {
+		initIndex = source
+		if index, err := loadIndex(); err == nil {
+			t.Errorf("loadIndex of %s = %+v, want an error", source, index)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/betterde/gonew/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listIndex string
+	listSort  string
+	listJSON  bool
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	RunE:  listTemplates,
+	Args:  cobra.NoArgs,
+	Short: "List the templates of a registry with their description and latest version",
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVar(&listIndex, "index", os.Getenv("GONEW_INDEX"), "Template index file or URL, in YAML or JSON, defaults to $GONEW_INDEX, the configured index or the built-in one")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "Order of the templates: name, popular (most generated first) or updated (latest release first)")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "Print the templates as JSON")
+}
+
+// listedTemplate is a template as printed by gonew list.
+type listedTemplate struct {
+	Name          string    `json:"name"`
+	Module        string    `json:"module"`
+	Description   string    `json:"description,omitempty"`
+	Latest        string    `json:"latest,omitempty"`
+	Updated       time.Time `json:"updated,omitzero"`
+	State         string    `json:"state,omitempty"`
+	Generations   int       `json:"generations,omitempty"`
+	LastGenerated time.Time `json:"last_generated,omitzero"`
+}
+
+func listTemplates(cmd *cobra.Command, args []string) error {
+	order, ok := templateOrders[listSort]
+	if !ok {
+		return fmt.Errorf("invalid --sort %s, must be one of name, popular or updated", listSort)
+	}
+
+	var index *registry.Index
+	var err error
+	if source := indexFile(listIndex); source != "" {
+		index, err = registry.Open(source)
+	} else {
+		index, err = registry.Default()
+	}
+	if err != nil {
+		return err
+	}
+
+	templates := make([]listedTemplate, len(index.Templates))
+	for i, entry := range index.Templates {
+		templates[i] = listedTemplate{
+			Name:          entry.Name,
+			Module:        entry.Module,
+			Description:   entry.Desc,
+			Latest:        entry.Latest,
+			Updated:       entry.Updated(),
+			State:         entry.State,
+			Generations:   entry.Generations,
+			LastGenerated: entry.LastGenerated,
+		}
+		// Indexes listing templates without publishing them leave the
+		// latest version to the module proxy.
+		if templates[i].Latest == "" {
+			if latest, err := latestVersion(cmd.Context(), entry.Module); err == nil {
+				templates[i].Latest = latest
+			} else {
+				log.Printf("warning: latest version of %s: %v", entry.Module, err)
+			}
+		}
+	}
+	sort.SliceStable(templates, func(a, b int) bool { return order(templates[a], templates[b]) })
+
+	out := cmd.OutOrStdout()
+	if listJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(templates); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLATEST\tUPDATED\tUSES\tMODULE\tDESCRIPTION")
+	for _, t := range templates {
+		latest, updated, uses := "-", "-", "-"
+		if t.Latest != "" {
+			latest = t.Latest
+		}
+		if !t.Updated.IsZero() {
+			updated = t.Updated.Format(time.DateOnly)
+		}
+		if t.Generations > 0 {
+			uses = fmt.Sprint(t.Generations)
+		}
+		description := t.Description
+		if t.State != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (%s)", description, t.State))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, latest, updated, uses, t.Module, description)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// templateOrders are the orders of --sort.
+var templateOrders = map[string]func(a, b listedTemplate) bool{
+	"name": func(a, b listedTemplate) bool {
+		return a.Name < b.Name
+	},
+	"popular": func(a, b listedTemplate) bool {
+		return a.Generations > b.Generations
+	},
+	"updated": func(a, b listedTemplate) bool {
+		return a.Updated.After(b.Updated)
+	},
+}
+
+// latestVersion asks the go command for the latest version of the module
+// mod, through the proxies templates are downloaded from.
+func latestVersion(ctx context.Context, mod string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Version}}", mod+"@latest")
+	command.Env = append(os.Environ(), fetchEnv()...)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("%v\n%s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,417 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/manifoldco/promptui"
+)
+
+var (
+	// promptTimeout, when positive, is how long a prompt waits for input
+	// before accepting its default.
+	promptTimeout time.Duration
+
+	// accessible selects plain line-based prompts without cursor movement
+	// or colors, which screen readers can follow.
+	accessible bool
+
+	// noPrompt answers every prompt with its default instead of asking, and
+	// fails prompts that have none, so gonew can run in CI pipelines.
+	noPrompt bool
+)
+
+// accessibleEnv reports whether the environment asks for accessible output.
+func accessibleEnv() bool {
+	return os.Getenv("ACCESSIBLE") != "" || os.Getenv("GONEW_ACCESSIBLE") != ""
+}
+
+// errPromptTimeout is returned by prompts that timed out without a default.
+var errPromptTimeout = errors.New("prompt timed out")
+
+// errNoPrompt is returned by prompts without a default under --no-prompt.
+var errNoPrompt = errors.New("prompting is disabled")
+
+var (
+	stdinOnce   sync.Once
+	stdinChunks chan []byte
+	stdinMu     sync.Mutex
+	stdinRest   []byte
+)
+
+// pumpStdin copies os.Stdin into stdinChunks for the lifetime of the process,
+// so a prompt can stop waiting for input without abandoning a blocked read.
+func pumpStdin() {
+	stdinChunks = make(chan []byte)
+	go func() {
+		defer close(stdinChunks)
+		for {
+			buf := make([]byte, 1024)
+			n, err := os.Stdin.Read(buf)
+			if n > 0 {
+				stdinChunks <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// timedInput is the standard input of one prompt. Its Read reports EOF once
+// the prompt has waited for input longer than the timeout, which makes
+// promptui return and restore the terminal.
+type timedInput struct {
+	timer    *time.Timer
+	timeout  time.Duration
+	timedOut bool
+}
+
+func newTimedInput(timeout time.Duration) *timedInput {
+	stdinOnce.Do(pumpStdin)
+	return &timedInput{timer: time.NewTimer(timeout), timeout: timeout}
+}
+
+func (t *timedInput) Read(p []byte) (int, error) {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+
+	// Input read ahead by an earlier prompt comes first.
+	if len(stdinRest) > 0 {
+		n := copy(p, stdinRest)
+		stdinRest = stdinRest[n:]
+		return n, nil
+	}
+
+	select {
+	case chunk, ok := <-stdinChunks:
+		if !ok {
+			return 0, io.EOF
+		}
+		// Typing restarts the clock.
+		t.timer.Reset(t.timeout)
+		n := copy(p, chunk)
+		stdinRest = chunk[n:]
+		return n, nil
+	case <-t.timer.C:
+		t.timedOut = true
+		return 0, io.EOF
+	}
+}
+
+func (t *timedInput) Close() error {
+	t.timer.Stop()
+	return nil
+}
+
+// promptInput returns the reader a prompt takes its input from.
+func promptInput() (io.Reader, *timedInput) {
+	if promptTimeout <= 0 {
+		return os.Stdin, nil
+	}
+	input := newTimedInput(promptTimeout)
+	return input, input
+}
+
+// runPrompt runs prompt, accepting its default when --prompt-timeout elapses
+// or under --no-prompt.
+func runPrompt(prompt *promptui.Prompt) (string, error) {
+	if noPrompt {
+		if prompt.Default == "" || prompt.IsConfirm {
+			return "", fmt.Errorf("%w: %s has no default", errNoPrompt, prompt.Label)
+		}
+		return prompt.Default, nil
+	}
+	if accessible {
+		return plainPrompt(prompt)
+	}
+	if promptTimeout <= 0 {
+		return prompt.Run()
+	}
+
+	input := newTimedInput(promptTimeout)
+	prompt.Stdin = input
+	value, err := prompt.Run()
+	input.Close()
+	if input.timedOut {
+		if prompt.Default == "" || prompt.IsConfirm {
+			return "", fmt.Errorf("%w: %s has no default", errPromptTimeout, prompt.Label)
+		}
+		fmt.Fprintf(os.Stderr, "%s: no input after %s, using %q\n", prompt.Label, promptTimeout, prompt.Default)
+		return prompt.Default, nil
+	}
+	return value, err
+}
+
+// runConfirm asks a yes or no question, answering def on an empty answer,
+// under --no-prompt or when --prompt-timeout elapses. help, if set, is shown
+// when the answer is "?".
+func runConfirm(label string, def bool, help string) (bool, error) {
+	if noPrompt {
+		return def, nil
+	}
+	prompt := promptui.Prompt{Label: label, IsConfirm: true}
+	if def {
+		prompt.Default = "y"
+	}
+	withHelp(&prompt, help)
+	_, err := runPrompt(&prompt)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, promptui.ErrAbort):
+		return false, nil
+	case errors.Is(err, errPromptTimeout):
+		return def, nil
+	}
+	return false, err
+}
+
+// runSelect runs prompt, choosing the item at fallback when --prompt-timeout
+// elapses or under --no-prompt.
+func runSelect(prompt *promptui.Select, fallback int) (int, error) {
+	if noPrompt {
+		return fallback, nil
+	}
+	if accessible {
+		return plainSelect(prompt, fallback)
+	}
+	if promptTimeout <= 0 {
+		i, _, err := prompt.Run()
+		return i, err
+	}
+
+	input := newTimedInput(promptTimeout)
+	prompt.Stdin = input
+	i, _, err := prompt.Run()
+	input.Close()
+	if input.timedOut {
+		fmt.Fprintf(os.Stderr, "%s: no input after %s, using the default\n", prompt.Label, promptTimeout)
+		return fallback, nil
+	}
+	return i, err
+}
+
+// helpError is returned by the validation of prompts answered with "?", to
+// show their help beneath them instead of an error.
+type helpError string
+
+func (e helpError) Error() string {
+	return string(e)
+}
+
+// withHelp makes prompt show help beneath it when the answer is "?". Prompts
+// only have one line for it, so the lines of help are joined.
+func withHelp(prompt *promptui.Prompt, help string) {
+	if help == "" {
+		return
+	}
+	validate := prompt.Validate
+	prompt.Label = fmt.Sprintf("%v (? for help)", prompt.Label)
+	prompt.Validate = func(input string) error {
+		if input == "?" {
+			return helpError(help)
+		}
+		if validate != nil {
+			return validate(input)
+		}
+		return nil
+	}
+
+	funcs := maps.Clone(promptui.FuncMap)
+	funcs["help"] = func(err error) string {
+		var help helpError
+		if errors.As(err, &help) {
+			return strings.Join(strings.Fields(string(help)), " ")
+		}
+		return ""
+	}
+	prompt.Templates = &promptui.PromptTemplates{
+		FuncMap:         funcs,
+		ValidationError: `{{ with help . }}{{ . | faint }}{{ else }}{{ ">>" | red }} {{ . | red }}{{ end }}`,
+	}
+}
+
+// withSelectHelp shows help beneath the items of prompt.
+func withSelectHelp(prompt *promptui.Select, help string) {
+	if help == "" {
+		return
+	}
+	prompt.Templates = &promptui.SelectTemplates{Details: fmt.Sprintf("{{ %q }}", help)}
+}
+
+// readLine reads one line from r a byte at a time, so no input meant for
+// the next prompt is consumed.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if len(line) > 0 && err == io.EOF {
+				return string(line), nil
+			}
+			return "", err
+		}
+	}
+}
+
+// plainPrompt asks for prompt's value on a single line, repeating the
+// question until the answer passes validation.
+func plainPrompt(prompt *promptui.Prompt) (string, error) {
+	out := bufio.NewWriter(os.Stdout)
+	for {
+		label := fmt.Sprint(prompt.Label)
+		switch {
+		case prompt.IsConfirm && strings.EqualFold(prompt.Default, "y"):
+			label += " (Y/n)"
+		case prompt.IsConfirm:
+			label += " (y/N)"
+		case prompt.Default != "":
+			label += fmt.Sprintf(" (default %s)", prompt.Default)
+		}
+		fmt.Fprintf(out, "%s: ", label)
+		out.Flush()
+
+		r, input := promptInput()
+		value, err := readLine(r)
+		if input != nil {
+			input.Close()
+			if input.timedOut {
+				fmt.Fprintln(out)
+				out.Flush()
+				if prompt.Default == "" || prompt.IsConfirm {
+					return "", fmt.Errorf("%w: %s has no default", errPromptTimeout, prompt.Label)
+				}
+				return prompt.Default, nil
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var help helpError
+		if prompt.Validate != nil && errors.As(prompt.Validate(value), &help) {
+			fmt.Fprintln(out, string(help))
+			continue
+		}
+		if prompt.IsConfirm {
+			if value == "" {
+				value = prompt.Default
+			}
+			if strings.EqualFold(value, "y") || strings.EqualFold(value, "yes") {
+				return value, nil
+			}
+			return "", promptui.ErrAbort
+		}
+		if value == "" {
+			value = prompt.Default
+		}
+		if prompt.Validate != nil {
+			if err := prompt.Validate(value); err != nil {
+				fmt.Fprintf(out, "Invalid answer: %v\n", err)
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+// plainSelect lists the items of prompt with numbers and asks for one,
+// choosing fallback on an empty answer.
+func plainSelect(prompt *promptui.Select, fallback int) (int, error) {
+	items, ok := prompt.Items.([]string)
+	if !ok {
+		return 0, fmt.Errorf("unsupported select items %T", prompt.Items)
+	}
+
+	var details *template.Template
+	if prompt.Templates != nil && prompt.Templates.Details != "" {
+		var err error
+		details, err = template.New("details").Funcs(promptui.FuncMap).Parse(prompt.Templates.Details)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	for {
+		fmt.Fprintf(out, "%s:\n", prompt.Label)
+		for i, item := range items {
+			fmt.Fprintf(out, "  %d. %s\n", i+1, item)
+		}
+		if details != nil {
+			fmt.Fprintf(out, "Enter a number or ? for help (default %d): ", fallback+1)
+		} else {
+			fmt.Fprintf(out, "Enter a number (default %d): ", fallback+1)
+		}
+		out.Flush()
+
+		r, input := promptInput()
+		value, err := readLine(r)
+		if input != nil {
+			input.Close()
+			if input.timedOut {
+				fmt.Fprintln(out)
+				out.Flush()
+				return fallback, nil
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if value == "" {
+			return fallback, nil
+		}
+		if value == "?" && details != nil {
+			if err := details.Execute(out, items[fallback]); err != nil {
+				return 0, err
+			}
+			fmt.Fprintln(out)
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || i < 1 || i > len(items) {
+			fmt.Fprintf(out, "Invalid answer: enter a number from 1 to %d\n", len(items))
+			continue
+		}
+		return i - 1, nil
+	}
+}
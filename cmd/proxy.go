@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/goproxy"
+)
+
+// Checksum database and go command settings applied when downloading
+// templates only, never to the go commands run in the generated project.
+var (
+	templateSumDB   string
+	templateNoSumDB string
+	templateGoFlags string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&templateSumDB, "template-sumdb", os.Getenv("GONEW_SUMDB"), "Checksum database verifying templates, such as a private one or off, also set by GONEW_SUMDB")
+	rootCmd.PersistentFlags().StringVar(&templateNoSumDB, "template-nosumdb", os.Getenv("GONEW_NOSUMDB"), "Comma-separated module path globs of templates not checked against the checksum database, also set by GONEW_NOSUMDB")
+	rootCmd.PersistentFlags().StringVar(&templateGoFlags, "template-goflags", os.Getenv("GONEW_GOFLAGS"), "GOFLAGS used when downloading templates, also set by GONEW_GOFLAGS")
+}
+
+// fetchEnv returns the environment go mod download runs with on top of the
+// process environment.
+func fetchEnv() []string {
+	var env []string
+	if templateSumDB != "" {
+		env = append(env, "GOSUMDB="+templateSumDB)
+	}
+	if templateNoSumDB != "" {
+		env = append(env, "GONOSUMDB="+templateNoSumDB)
+	}
+	if templateGoFlags != "" {
+		env = append(env, "GOFLAGS="+templateGoFlags)
+	}
+	return env
+}
+
+// downloadFallback tries each entry of GOPROXY on its own after downloading
+// ver failed with err, whatever separates them: the go command only moves on
+// after "," when a proxy answers 404 or 410. If every proxy fails, the error
+// reports what each of them answered, and whether the checksum database
+// rejected the module.
+func downloadFallback(ctx context.Context, ver string, err error) (*moduleInfo, error) {
+	out, envErr := exec.CommandContext(ctx, "go", "env", "GOPROXY").Output()
+	if envErr != nil {
+		return nil, err
+	}
+	proxies := goproxy.Parse(strings.TrimSpace(string(out)))
+
+	mod, query, _ := strings.Cut(ver, "@")
+	client := &http.Client{Timeout: 30 * time.Second}
+	var attempts []goproxy.Attempt
+	checksum := goproxy.ChecksumFailure(err.Error())
+	for i, proxy := range proxies {
+		if proxy == goproxy.Off {
+			attempts = append(attempts, goproxy.Attempt{Proxy: proxy, Err: fmt.Errorf("module downloads are disabled")})
+			break
+		}
+		info, err := goModDownload(ctx, ver, "GOPROXY="+proxy)
+		if err == nil {
+			if i > 0 {
+				log.Printf("downloaded %s from %s after %s", ver, proxy, describeAttempts(attempts))
+			}
+			return info, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		attempt := goproxy.Attempt{Proxy: proxy, Err: err}
+		if goproxy.IsHTTP(proxy) {
+			if status, probeErr := goproxy.Probe(ctx, client, proxy, mod, query); probeErr == nil {
+				attempt.Status = status
+			}
+		}
+		checksum = checksum || goproxy.ChecksumFailure(err.Error())
+		attempts = append(attempts, attempt)
+	}
+	if len(attempts) == 0 {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "downloading %s failed with every proxy of GOPROXY:", ver)
+	for _, attempt := range attempts {
+		fmt.Fprintf(&b, "\n  %s", attempt)
+	}
+	if checksum {
+		fmt.Fprintf(&b, "\nthe checksum database could not verify %s; for private templates, add it to GONOSUMDB or GOPRIVATE", mod)
+	}
+	return nil, fmt.Errorf("%s", b.String())
+}
+
+// describeAttempts lists the proxies that failed and their status.
+func describeAttempts(attempts []goproxy.Attempt) string {
+	var failed []string
+	for _, attempt := range attempts {
+		if attempt.Status != "" {
+			failed = append(failed, fmt.Sprintf("%s failed (%s)", attempt.Proxy, attempt.Status))
+		} else {
+			failed = append(failed, attempt.Proxy+" failed")
+		}
+	}
+	return strings.Join(failed, ", ")
+}
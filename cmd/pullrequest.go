@@ -0,0 +1,168 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/build"
+	"github.com/betterde/gonew/internal/git"
+	"github.com/betterde/gonew/internal/pullrequest"
+)
+
+var (
+	createPR   bool
+	prRemote   string
+	prProvider string
+	prAPI      string
+)
+
+func init() {
+	initCmd.Flags().BoolVar(&createPR, "create-pr", false, "Push the --branch and open a pull request for it on GitHub or GitLab, with the token of GITHUB_TOKEN or GITLAB_TOKEN")
+	initCmd.Flags().StringVar(&prRemote, "pr-remote", "origin", "Git remote to push the branch to and open the pull request on")
+	initCmd.Flags().StringVar(&prProvider, "pr-provider", "", "Host of the remote, github or gitlab, detected from its URL by default")
+	initCmd.Flags().StringVar(&prAPI, "pr-api", "", "Base URL of the GitHub or GitLab API, for self-hosted instances")
+}
+
+// openPullRequest pushes the scaffold branch and opens a pull request for it
+// into the current branch, adding its URL to s.
+func openPullRequest(ctx context.Context, branch *branchTarget, s *summary, components []*component, inputs map[string]string, features map[string]bool) error {
+	remote, err := git.Run(ctx, branch.repo, "remote", "get-url", prRemote)
+	if err != nil {
+		return err
+	}
+	location, err := git.ModulePath(remote)
+	if err != nil {
+		return err
+	}
+	host, repo, _ := strings.Cut(location, "/")
+	base, err := git.Run(ctx, branch.repo, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("the pull request needs a current branch to merge into: %v", err)
+	}
+
+	provider := prProvider
+	if provider == "" {
+		provider = pullrequest.Detect(host)
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if provider == pullrequest.GitLab {
+		token = os.Getenv("GITLAB_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("no token to open a %s pull request with", provider)
+	}
+
+	request := pullrequest.Request{API: prAPI, Host: host, Repo: repo, Head: scaffoldBranch, Base: base, Token: token}
+	request.Title, request.Body, err = pullRequestText(components, inputs, features)
+	if err != nil {
+		return err
+	}
+
+	if _, err := git.Run(ctx, branch.repo, "push", "--quiet", prRemote, "refs/heads/"+scaffoldBranch); err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	link, err := pullrequest.Open(ctx, client, provider, request)
+	if err != nil {
+		return err
+	}
+	s.addArtifact("Pull request", link)
+	return nil
+}
+
+// pullRequestText renders the pull request title and description of the
+// first component declaring them, and appends the provenance of the project
+// to the description.
+func pullRequestText(components []*component, inputs map[string]string, features map[string]bool) (title, body string, err error) {
+	title = "Scaffold " + dstMod
+	for _, c := range components {
+		pr := c.config.PullRequest
+		if pr.Title == "" && pr.Body == "" {
+			continue
+		}
+		data := c.context(inputs, features)
+		if pr.Title != "" {
+			out, err := c.engine.Render("pull_request title", pr.Title, data)
+			if err != nil {
+				return "", "", &templateError{component: c, file: "pull_request title", err: err}
+			}
+			title = strings.TrimSpace(string(out))
+		}
+		if pr.Body != "" {
+			out, err := c.engine.Render("pull_request body", pr.Body, data)
+			if err != nil {
+				return "", "", &templateError{component: c, file: "pull_request body", err: err}
+			}
+			body = strings.TrimSpace(string(out)) + "\n\n"
+		}
+		break
+	}
+	return title, body + provenance(components, inputs, features), nil
+}
+
+// provenance describes in Markdown how the project was generated: by which
+// gonew, from which templates, and with which answers, leaving out secret
+// and generated ones.
+func provenance(components []*component, inputs map[string]string, features map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Provenance\n\n")
+	fmt.Fprintf(&b, "- Generated by %s %s\n", build.Name, build.Version)
+	fmt.Fprintf(&b, "- Templates: %s\n", templateVersions(components))
+	fmt.Fprintf(&b, "- Module: %s\n", dstMod)
+	if layoutName != "" {
+		fmt.Fprintf(&b, "- Layout: %s\n", layoutName)
+	}
+	var selected []string
+	for name, ok := range features {
+		if ok {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) > 0 {
+		sort.Strings(selected)
+		fmt.Fprintf(&b, "- Features: %s\n", strings.Join(selected, ", "))
+	}
+
+	var names []string
+	for _, variable := range config.Variables {
+		if _, ok := inputs[variable.Name]; ok && !variable.Secret && variable.Generated == "" {
+			names = append(names, variable.Name)
+		}
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(&b, "\n| Variable | Answer |\n| --- | --- |\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "| %s | %s |\n", name, strings.ReplaceAll(inputs[name], "|", "\\|"))
+		}
+	}
+	return b.String()
+}
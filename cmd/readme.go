@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path"
+	"strings"
+
+	"github.com/betterde/gonew/internal/readme"
+	"github.com/betterde/gonew/internal/sandbox"
+)
+
+// writeComposedReadme composes README.md from the compose_readme section of
+// the first component declaring one, unless the template generates its own.
+func writeComposedReadme(box *sandbox.Sandbox, components []*component, inputs map[string]string, features map[string]bool, written map[string]bool) error {
+	if written[readme.FileName] || !onlySelected(readme.FileName) {
+		return nil
+	}
+	for _, c := range components {
+		section := c.config.ComposeReadme
+		if section == nil {
+			continue
+		}
+		data := c.context(inputs, features)
+		render := func(name, text string) (string, error) {
+			out, err := c.engine.Render("compose_readme "+name, text, data)
+			if err != nil {
+				return "", &templateError{component: c, file: "compose_readme " + name, err: err}
+			}
+			return strings.TrimSpace(string(out)), nil
+		}
+
+		r := readme.Readme{Title: path.Base(dstMod)}
+		if section.Title != "" {
+			title, err := render("title", section.Title)
+			if err != nil {
+				return err
+			}
+			r.Title = title
+		}
+		description, err := render("description", section.Description)
+		if err != nil {
+			return err
+		}
+		r.Description = description
+
+		for _, b := range section.Badges {
+			if b.Name != "" {
+				badge, err := readme.Builtin(b.Name, dstMod)
+				if err != nil {
+					warn(warnBadgeSkipped, readme.FileName, "skipping %v", err)
+					continue
+				}
+				r.Badges = append(r.Badges, badge)
+				continue
+			}
+			var badge readme.Badge
+			for _, field := range []struct {
+				name, text string
+				value      *string
+			}{
+				{"badge alt", b.Alt, &badge.Alt},
+				{"badge image", b.Image, &badge.Image},
+				{"badge link", b.Link, &badge.Link},
+			} {
+				if *field.value, err = render(field.name, field.text); err != nil {
+					return err
+				}
+			}
+			if badge.Link == "" {
+				badge.Link = badge.Image
+			}
+			r.Badges = append(r.Badges, badge)
+		}
+
+		for _, command := range section.GettingStarted {
+			command, err := render("getting_started", command)
+			if err != nil {
+				return err
+			}
+			if command != "" {
+				r.GettingStarted = append(r.GettingStarted, command)
+			}
+		}
+		for _, s := range section.Sections {
+			title, err := render("section title", s.Title)
+			if err != nil {
+				return err
+			}
+			body, err := render("section "+s.Title, s.Body)
+			if err != nil {
+				return err
+			}
+			r.Sections = append(r.Sections, readme.Section{Title: title, Body: body})
+		}
+
+		if err := box.WriteFile(readme.FileName, r.Markdown(), 0666); err != nil {
+			return err
+		}
+		written[readme.FileName] = true
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/betterde/gonew/internal/release"
+	"github.com/betterde/gonew/internal/sandbox"
+)
+
+// writeReleaseFiles generates the release files declared by the first
+// component with a release section, skipping those the template generates
+// itself.
+func writeReleaseFiles(box *sandbox.Sandbox, components []*component, inputs map[string]string, features map[string]bool, written map[string]bool) error {
+	for _, c := range components {
+		section := c.config.Release
+		if section == nil {
+			continue
+		}
+		if section.Feature != "" && !features[section.Feature] {
+			return nil
+		}
+		data := c.context(inputs, features)
+		render := func(name, text string) (string, error) {
+			out, err := c.engine.Render("release "+name, text, data)
+			if err != nil {
+				return "", &templateError{component: c, file: "release " + name, err: err}
+			}
+			return strings.TrimSpace(string(out)), nil
+		}
+		tool, err := render("tool", section.Tool)
+		if err != nil {
+			return err
+		}
+		mainPkg, err := render("main", section.Main)
+		if err != nil {
+			return err
+		}
+
+		files, err := release.Files(release.Project{Name: path.Base(dstMod), Module: dstMod, Main: mainPkg}, tool)
+		if err != nil {
+			return &templateError{component: c, file: "release tool", err: err}
+		}
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rel := filepath.FromSlash(name)
+			if written[rel] || !onlySelected(name) {
+				continue
+			}
+			if err := box.WriteFile(rel, files[name], 0666); err != nil {
+				return err
+			}
+			written[rel] = true
+		}
+		return nil
+	}
+	return nil
+}
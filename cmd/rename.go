@@ -0,0 +1,165 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/betterde/gonew/internal/sandbox"
+	"github.com/betterde/gonew/pkg/rewrite"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+var renameDir string
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename <new-module>",
+	RunE:  renameProject,
+	Args:  cobra.ExactArgs(1),
+	Short: "Change the module path of an existing project in place",
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+
+	renameCmd.Flags().StringVar(&renameDir, "dir", ".", "Root directory of the module to rename")
+}
+
+func renameProject(cmd *cobra.Command, args []string) error {
+	newMod := args[0]
+	if err := module.CheckPath(newMod); err != nil {
+		return fmt.Errorf("invalid module name: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(renameDir, "go.mod"))
+	if err != nil {
+		return err
+	}
+	oldMod := modfile.ModulePath(data)
+	if oldMod == "" {
+		return fmt.Errorf("%s: no module path", filepath.Join(renameDir, "go.mod"))
+	}
+	if oldMod == newMod {
+		log.Printf("module is already %s", newMod)
+		return nil
+	}
+
+	changed, err := renameModule(renameDir, oldMod, newMod)
+	if err != nil {
+		return err
+	}
+	log.Printf("renamed %s to %s, %d files changed", oldMod, newMod, changed)
+	return nil
+}
+
+// renameModule rewrites the module at dir from oldMod to newMod: its go.mod,
+// the imports of its Go files and the known config files embedding the
+// module path. It returns the number of files changed.
+func renameModule(dir, oldMod, newMod string) (int, error) {
+	box, err := sandbox.New(dir, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int
+	err = filepath.WalkDir(dir, func(src string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, src)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip version control metadata, dependencies, test data and
+			// nested modules. Other dot directories, such as .github, hold
+			// config files too.
+			name := d.Name()
+			if rel != "." && (vcsDirs[name] || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(src, "go.mod")); rel != "." && err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		var fix func([]byte) ([]byte, error)
+		switch {
+		case rel == "go.mod":
+			fix = func(data []byte) ([]byte, error) { return fixGoMod(data, newMod), nil }
+		case strings.HasSuffix(rel, ".go"):
+			// The go command ignores the Go files of dot and underscore
+			// directories.
+			if goIgnored(rel) {
+				return nil
+			}
+			fix = func(data []byte) ([]byte, error) { return fixGo(data, rel, oldMod, newMod, nil), nil }
+		default:
+			if _, ok := rewrite.Lookup(rel); !ok {
+				return nil
+			}
+			fix = func(data []byte) ([]byte, error) { return fixConfig(data, rel, oldMod, newMod, nil) }
+		}
+
+		data, err := box.ReadFile(rel)
+		if err != nil {
+			return err
+		}
+		fixed, err := fix(data)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, fixed) {
+			return nil
+		}
+		changed++
+		return box.WriteFile(rel, fixed, 0666)
+	})
+	return changed, err
+}
+
+// vcsDirs are the directories of version control systems.
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".bzr": true}
+
+// goIgnored reports whether the go command ignores the Go file at rel, in a
+// directory whose name starts with a dot or an underscore.
+func goIgnored(rel string) bool {
+	for _, elem := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+		if elem != "." && (strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/betterde/gonew/pkg/rewrite"
+)
+
+func TestRenameModule(t *testing.T) {
+	files := map[string]string{
+		"go.mod":                      "module example.com/old\n\ngo 1.22\n",
+		"main.go":                     "package main\n\nimport _ \"example.com/old/internal/db\"\n\nfunc main() {}\n",
+		"internal/db/db.go":           "package db\n",
+		".golangci.yml":               "local-prefixes: example.com/old\n",
+		".github/workflows/ci.yml":    "run: go build -ldflags -X=example.com/old/internal/version.Version=1\n",
+		".github/README.md":           "example.com/old\n",
+		"_tools/tools.go":             "package tools\n\nimport _ \"example.com/old/internal/db\"\n",
+		"vendor/example.com/x/x.go":   "package x\n\nimport _ \"example.com/old/internal/db\"\n",
+		"nested/go.mod":               "module example.com/old/nested\n",
+		"nested/nested.go":            "package nested\n\nimport _ \"example.com/old/internal/db\"\n",
+		".git/config":                 "url = example.com/old\n",
+		"testdata/.golangci.yml":      "local-prefixes: example.com/old\n",
+		"internal/db/testdata/go.mod": "module example.com/old/internal/db/testdata\n",
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changed, err := renameModule(dir, "example.com/old", "example.com/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := []string{"go.mod", "main.go", ".golangci.yml", ".github/workflows/ci.yml"}
+	if changed != len(renamed) {
+		t.Errorf("renameModule changed %d files, want %d", changed, len(renamed))
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := content
+		for _, r := range renamed {
+			if r == name {
+				want = strings.ReplaceAll(content, "example.com/old", "example.com/new")
+			}
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
+
+func TestRenameModuleConfigError(t *testing.T) {
+	rewrite.Register("*.renamefail", func([]byte, rewrite.Options) ([]byte, error) {
+		return nil, errors.New("cannot rewrite")
+	})
+	dir := t.TempDir()
+	for name, content := range map[string]string{"go.mod": "module example.com/old\n", "app.renamefail": "example.com/old\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := renameModule(dir, "example.com/old", "example.com/new"); err == nil || !strings.Contains(err.Error(), "cannot rewrite") {
+		t.Errorf("renameModule = %v, want the error of the config handler", err)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/betterde/gonew/internal/build"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/manifoldco/promptui"
+)
+
+// errorReport is the file a report of template errors is written to
+// without asking.
+var errorReport string
+
+// defaultErrorReport is the file offered for the report when asking.
+const defaultErrorReport = "gonew-error-report.txt"
+
+// templateError is a failure rendering a file of a template, a problem for
+// its maintainers rather than for the user.
+type templateError struct {
+	component *component
+	file      string
+	err       error
+}
+
+func (e *templateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *templateError) Unwrap() error {
+	return e.err
+}
+
+// errorLinePatterns find the line of a failure in the error messages of the
+// gotemplate and pongo2 engines.
+var errorLinePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`template: [^:]*:(\d+)`),
+	regexp.MustCompile(`Line (\d+)`),
+}
+
+// reportTemplateError offers to write a report of err, if it is a template
+// error, that the user can attach to an issue of the template repository.
+// Answers and local paths are left out of the report.
+func reportTemplateError(err error, inputs map[string]string) {
+	var te *templateError
+	if !errors.As(err, &te) {
+		return
+	}
+
+	filename := errorReport
+	if filename == "" {
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("The template failed, write an error report for its maintainers to %s", defaultErrorReport),
+			IsConfirm: true,
+		}
+		if _, err := runPrompt(&prompt); err != nil {
+			return
+		}
+		filename = defaultErrorReport
+	}
+
+	version := te.component.query
+	if te.component.info != nil && te.component.info.Version != "" {
+		version = te.component.info.Version
+	}
+	engine := te.component.config.Engine
+	if engine == "" {
+		engine = render.DefaultEngine
+	}
+	line := "unknown"
+	for _, pattern := range errorLinePatterns {
+		if m := pattern.FindStringSubmatch(te.err.Error()); m != nil {
+			line = m[1]
+			break
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "gonew error report\n\n")
+	fmt.Fprintf(&b, "gonew:    %s (%s, %s/%s)\n", build.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "template: %s@%s\n", te.component.mod, version)
+	fmt.Fprintf(&b, "engine:   %s\n", engine)
+	fmt.Fprintf(&b, "file:     %s\n", te.file)
+	fmt.Fprintf(&b, "line:     %s\n", line)
+	fmt.Fprintf(&b, "error:    %s\n", sanitize(te.err.Error(), te.component, inputs))
+
+	if err := os.WriteFile(filename, []byte(b.String()), 0666); err != nil {
+		log.Printf("writing error report: %v", err)
+		return
+	}
+	log.Printf("error report written to %s, attach it to an issue of %s", filename, te.component.mod)
+}
+
+// sanitize removes answers, which may be secrets, and local paths from text.
+func sanitize(text string, c *component, inputs map[string]string) string {
+	var replacements []string
+	if c.info != nil && c.info.Dir != "" {
+		replacements = append(replacements, c.info.Dir, "<template>")
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		replacements = append(replacements, home, "~")
+	}
+
+	// Replace longer answers first, they may contain shorter ones. Very
+	// short answers would mangle the message more than they reveal.
+	names := make([]string, 0, len(inputs))
+	for name, value := range inputs {
+		if len(value) >= 3 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return len(inputs[names[i]]) > len(inputs[names[j]]) })
+	for _, name := range names {
+		replacements = append(replacements, inputs[name], "<"+name+">")
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
@@ -22,24 +22,118 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"github.com/betterde/gonew/internal/build"
+	"context"
+	"log"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
+	"os/signal"
 
+	"github.com/betterde/gonew/internal/build"
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/betterde/gonew/internal/settings"
+	"github.com/betterde/gonew/internal/terminal"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pprofAddr  string
+	configFile string
+	// userSettings is the configuration read from --config.
+	userSettings = &settings.Settings{}
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:     build.Name,
-	Short:   build.Desc,
-	Version: build.Version,
+	Use:               build.Name,
+	Short:             build.Desc,
+	Version:           build.Version,
+	PersistentPreRunE: setup,
+
+	// Commands return their errors, which Execute logs once their deferred
+	// cleanup has run.
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file, defaults to config.yaml in $XDG_CONFIG_HOME/gonew or $GONEW_HOME/config")
+	rootCmd.PersistentFlags().StringVar(&pprofAddr, "pprof", "", "Serve runtime profiling data on this address, e.g. localhost:6060")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", accessibleEnv(), "Use plain prompts suited to screen readers, also enabled by ACCESSIBLE or GONEW_ACCESSIBLE and in terminals unable to show interactive prompts")
+	rootCmd.PersistentFlags().BoolVar(&noPrompt, "no-prompt", false, "Never prompt, accepting defaults and failing on anything without one, for CI pipelines")
+}
+
+// setup prepares every command: it moves files left by earlier versions to
+// their current location, reads the configuration, picks the prompts the
+// terminal supports and starts profiling.
+func setup(cmd *cobra.Command, args []string) error {
+	// The arguments are valid by now, so errors from here on are not about
+	// the usage of the command.
+	cmd.SilenceUsage = true
+
+	moved, err := paths.Migrate()
+	for _, from := range moved {
+		log.Printf("moved %s to its XDG location", from)
+	}
+	if err != nil {
+		log.Printf("warning: moving files of an earlier gonew: %v", err)
+	}
+
+	if configFile == "" {
+		if configFile, err = settings.Path(); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(configFile); err != nil {
+		// Only the default configuration file is optional.
+		return err
+	}
+	if userSettings, err = settings.Load(configFile); err != nil {
+		return err
+	}
+
+	// Interactive prompts garble dumb terminals and hang in some IDE
+	// consoles, which get the plain ones unless --accessible=false.
+	if !accessible && !cmd.Flags().Changed("accessible") {
+		if ok, _ := terminal.Interactive(); !ok {
+			accessible = true
+		}
+	}
+	startProfiling(cmd, args)
+	return nil
+}
+
+// startProfiling serves net/http/pprof in the background when --pprof is set,
+// so the memory and goroutine use of a long render can be inspected.
+func startProfiling(cmd *cobra.Command, args []string) {
+	if pprofAddr == "" {
+		return
+	}
+	go func() {
+		log.Printf("serving pprof on http://%s/debug/pprof/", pprofAddr)
+		if err := http.ListenAndServe(pprofAddr, nil); err != nil {
+			log.Printf("pprof: %v", err)
+		}
+	}()
+}
+
+// ExecuteContext runs gonew with args, such as "init", "example.com/template",
+// in process under ctx and returns the error the command failed with.
+// Progress events of the generation go to the progress.Handler of ctx, see
+// progress.NewContext. Flags keep their values between calls, which must not
+// run concurrently.
+func ExecuteContext(ctx context.Context, args ...string) error {
+	rootCmd.SetArgs(args)
+	defer rootCmd.SetArgs(nil)
+	return rootCmd.ExecuteContext(ctx)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	// Interrupting cancels the commands' context, stopping the go command,
+	// formatters and other processes they run.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteContextReturnsErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defer func(config, dir string) { configFile, renameDir = config, dir }(configFile, renameDir)
+	dir := t.TempDir()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"rename", "--dir", dir, "example.com/new"}, "go.mod"},
+		{[]string{"rename", "--dir", dir, "Not A Module"}, "invalid module name"},
+		{[]string{"tree", "--layout", "none", dir}, "go.mod"},
+		{[]string{"template", "state", "example.com/tpl", "retired"}, "unknown state"},
+		{[]string{"--config", filepath.Join(dir, "missing.yaml"), "list"}, "missing.yaml"},
+	}
+	for _, tt := range tests {
+		configFile = ""
+		err := ExecuteContext(t.Context(), tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("gonew %s = %v, want an error about %s", strings.Join(tt.args, " "), err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/betterde/gonew/internal/bundle"
+	"github.com/betterde/gonew/internal/cache"
+	"github.com/betterde/gonew/internal/git"
+	"github.com/betterde/gonew/internal/lockfile"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// A resolver makes the template of a component available in a local
+// directory, from wherever its source says it lives.
+type resolver interface {
+	resolve(ctx context.Context, c *component) (*moduleInfo, error)
+	// cacheable reports whether the manifest may be cached under the
+	// module path and query of the component, for --prompt-first.
+	cacheable() bool
+}
+
+// moduleSource is a template published as a Go module, downloaded with the
+// go command.
+type moduleSource struct{}
+
+func (moduleSource) resolve(ctx context.Context, c *component) (*moduleInfo, error) {
+	return downloadModule(ctx, c.String())
+}
+
+func (moduleSource) cacheable() bool { return true }
+
+// bundleSource is a bundle file written by template bundle.
+type bundleSource struct {
+	filename string
+}
+
+func (s bundleSource) resolve(ctx context.Context, c *component) (*moduleInfo, error) {
+	return extractBundle(s.filename)
+}
+
+func (bundleSource) cacheable() bool { return true }
+
+// localSource is a template directory used in place, without publishing
+// it. It changes as it is worked on, so caching its manifest would only
+// shadow the published one.
+type localSource struct {
+	dir string
+}
+
+func (s localSource) resolve(ctx context.Context, c *component) (*moduleInfo, error) {
+	return &moduleInfo{Dir: s.dir}, nil
+}
+
+func (localSource) cacheable() bool { return false }
+
+// gitSource is a template in a git repository at a branch, tag or commit,
+// which need not be published as a module. Branches move, so its manifest
+// is not cached either.
+type gitSource struct {
+	url string
+	ref string
+	// info is the checkout, made once.
+	info *moduleInfo
+}
+
+func (s *gitSource) resolve(ctx context.Context, c *component) (*moduleInfo, error) {
+	if s.info != nil {
+		return s.info, nil
+	}
+	info, err := s.checkout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.info = info
+	return info, nil
+}
+
+func (*gitSource) cacheable() bool { return false }
+
+// parseSource returns the component of a single init source: a bundle file,
+// a local directory, a git URL or a module query.
+func parseSource(ctx context.Context, source string) (*component, error) {
+	switch {
+	case bundle.IsBundle(source):
+		index, err := bundle.ReadIndex(source)
+		if err != nil {
+			return nil, err
+		}
+		return &component{mod: index.Module, query: index.Version, source: bundleSource{filename: source}}, nil
+	case isLocalPath(source):
+		return localComponent(source)
+	case isGitURL(source):
+		return gitComponent(ctx, source)
+	}
+	mod, query, ok := strings.Cut(source, "@")
+	if !ok {
+		query = "latest"
+	}
+	if err := module.CheckPath(mod); err != nil {
+		return nil, fmt.Errorf("invalid source module name: %v", err)
+	}
+	return &component{mod: mod, query: query, source: moduleSource{}}, nil
+}
+
+// isLocalPath reports whether source is a filesystem path rather than a
+// module path: like the go command, paths are absolute or start with "."
+// or "..".
+func isLocalPath(source string) bool {
+	if filepath.IsAbs(source) || source == "." || source == ".." {
+		return true
+	}
+	for _, prefix := range []string{"./", "../", "." + string(filepath.Separator), ".." + string(filepath.Separator)} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// localComponent returns the component of the template in dir, named after
+// the module path of its go.mod.
+func localComponent(dir string) (*component, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := templateModule(abs)
+	if err != nil {
+		return nil, fmt.Errorf("local template: %v", err)
+	}
+	return &component{mod: mod, query: "local", source: localSource{dir: abs}, info: &moduleInfo{Dir: abs}}, nil
+}
+
+// templateModule returns the module path declared by the go.mod in dir.
+func templateModule(dir string) (string, error) {
+	filename := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	mod := modfile.ModulePath(data)
+	if mod == "" {
+		return "", fmt.Errorf("%s declares no module path", filename)
+	}
+	return mod, nil
+}
+
+// isGitURL reports whether source is the URL of a git repository, in any
+// of the forms git accepts: https://host/org/repo.git, ssh://host/org/repo
+// or git@host:org/repo.git.
+func isGitURL(source string) bool {
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://", "file://"} {
+		if strings.HasPrefix(source, scheme) {
+			return true
+		}
+	}
+	// scp-like syntax: user@host:path
+	user, rest, ok := strings.Cut(source, "@")
+	return ok && user != "" && !strings.Contains(user, "/") && strings.Contains(strings.SplitN(rest, "/", 2)[0], ":")
+}
+
+// splitGitRef splits a git source such as
+// https://github.com/org/tpl.git@feature/x into its URL and ref. The ref
+// follows the first "@" in the path of the URL, so user names before the
+// host are left alone.
+func splitGitRef(source string) (url, ref string) {
+	pathStart := 0
+	if _, after, ok := strings.Cut(source, "://"); ok {
+		pathStart = len(source) - len(after)
+		if i := strings.Index(after, "/"); i >= 0 {
+			pathStart += i
+		}
+	} else if i := strings.Index(source, ":"); i >= 0 {
+		pathStart = i
+	}
+	if i := strings.Index(source[pathStart:], "@"); i >= 0 {
+		return source[:pathStart+i], source[pathStart+i+1:]
+	}
+	return source, ""
+}
+
+// gitComponent checks out the template of a git source, to name its
+// component after the module path of its go.mod.
+func gitComponent(ctx context.Context, source string) (*component, error) {
+	url, ref := splitGitRef(source)
+	s := &gitSource{url: url, ref: ref}
+	info, err := s.resolve(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	mod, err := templateModule(info.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+	query := ref
+	if query == "" {
+		query = "HEAD"
+	}
+	return &component{mod: mod, query: query, source: s}, nil
+}
+
+// checkout fetches the ref of the repository into a bare repository kept in
+// the cache and extracts the commit it points at into a directory of its
+// own, which is reused for as long as the ref points there. Version is the
+// commit hash.
+func (s *gitSource) checkout(ctx context.Context) (*moduleInfo, error) {
+	root, err := cache.Dir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(s.url))
+	base := filepath.Join(root, "git", hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return nil, err
+	}
+	repo := filepath.Join(base, "repo.git")
+
+	// Concurrent runs share the repository, and git refuses to work on
+	// it while another process holds its locks.
+	unlock, err := lockfile.Lock(repo)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(repo); os.IsNotExist(err) {
+		if _, err := git.Run(ctx, base, "init", "--quiet", "--bare", repo); err != nil {
+			return nil, err
+		}
+	}
+	ref := s.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := git.Run(ctx, repo, "fetch", "--quiet", "--depth", "1", s.url, ref); err != nil {
+		return nil, err
+	}
+	commit, err := git.Run(ctx, repo, "rev-parse", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(base, commit)
+	if _, err := os.Stat(dir); err == nil {
+		return &moduleInfo{Dir: dir, Version: commit}, nil
+	}
+	tmp, err := os.MkdirTemp(base, commit+".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := git.Run(ctx, repo, "--work-tree", tmp, "checkout", "--quiet", commit, "--", "."); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	return &moduleInfo{Dir: dir, Version: commit}, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/sandbox"
+)
+
+// artifact is a generated output other than a file, such as a dashboard URL
+// or the path of a credentials file, reported once the project is written.
+type artifact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// summary collects the outcome of init: the files written, and the messages
+// and artifacts registered by the template manifests and by the templates
+// while they render.
+type summary struct {
+	Module    string     `json:"module"`
+	Dir       string     `json:"dir"`
+	Files     []string   `json:"files"`
+	Messages  []string   `json:"messages,omitempty"`
+	Artifacts []artifact `json:"artifacts,omitempty"`
+	Readme    string     `json:"readme,omitempty"`
+	Warnings  []warning  `json:"warnings,omitempty"`
+}
+
+// funcs returns the template functions registering messages and artifacts,
+// both render to nothing.
+func (s *summary) funcs() map[string]any {
+	return map[string]any{
+		"message": func(text string) string {
+			s.addMessage(text)
+			return ""
+		},
+		"artifact": func(name, value string) string {
+			s.addArtifact(name, value)
+			return ""
+		},
+	}
+}
+
+func (s *summary) addMessage(text string) {
+	for _, message := range s.Messages {
+		if message == text {
+			return
+		}
+	}
+	s.Messages = append(s.Messages, text)
+}
+
+func (s *summary) addArtifact(name, value string) {
+	for _, a := range s.Artifacts {
+		if a.Name == name && a.Value == value {
+			return
+		}
+	}
+	s.Artifacts = append(s.Artifacts, artifact{Name: name, Value: value})
+}
+
+// collectOutputs renders the messages and artifacts of the component's manifest into s.
+func collectOutputs(s *summary, c *component, inputs map[string]string, features map[string]bool) error {
+	data := c.context(inputs, features)
+	for i, message := range c.config.Messages {
+		out, err := c.engine.Render(fmt.Sprintf("messages[%d]", i), message, data)
+		if err != nil {
+			return err
+		}
+		s.addMessage(strings.TrimSpace(string(out)))
+	}
+	for _, a := range c.config.Artifacts {
+		out, err := c.engine.Render("artifact "+a.Name, a.Value, data)
+		if err != nil {
+			return err
+		}
+		s.addArtifact(a.Name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// renderReadme renders the TEMPLATE_README.md of each component that has
+// one, and either writes it to the project or adds it to s for display.
+func renderReadme(s *summary, box *sandbox.Sandbox, components []*component, inputs map[string]string, features map[string]bool, written map[string]bool) error {
+	var generated []byte
+	for _, c := range components {
+		data, err := os.ReadFile(filepath.Join(c.root, project.ReadmeFileName))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		out, err := c.engine.Render(project.ReadmeFileName, string(data), c.context(inputs, features))
+		if err != nil {
+			return &templateError{component: c, file: project.ReadmeFileName, err: err}
+		}
+
+		if c.config.Readme == project.ReadmeWrite {
+			if len(generated) > 0 {
+				generated = append(generated, '\n')
+			}
+			generated = append(generated, out...)
+			continue
+		}
+		s.Readme += string(out)
+	}
+
+	if len(generated) == 0 || !onlySelected(project.ReadmeDestination) {
+		return nil
+	}
+	name := filepath.FromSlash(project.ReadmeDestination)
+	if written[name] {
+		return fmt.Errorf("%s is generated by the template and by %s", project.ReadmeDestination, project.ReadmeFileName)
+	}
+	if err := box.WriteFile(name, generated, 0666); err != nil {
+		return err
+	}
+	written[name] = true
+	return nil
+}
+
+// print writes the messages and artifacts for people, or the whole summary
+// as JSON.
+func (s *summary) print(w io.Writer, asJSON bool) error {
+	if asJSON {
+		sort.Strings(s.Files)
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(s)
+	}
+
+	for _, message := range s.Messages {
+		fmt.Fprintln(w, message)
+	}
+	if len(s.Artifacts) > 0 {
+		fmt.Fprintln(w, "Artifacts:")
+		for _, a := range s.Artifacts {
+			fmt.Fprintf(w, "  %s: %s\n", a.Name, a.Value)
+		}
+	}
+	if s.Readme != "" {
+		fmt.Fprintf(w, "\n%s", s.Readme)
+	}
+	return nil
+}
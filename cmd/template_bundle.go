@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/betterde/gonew/internal/bundle"
+	"github.com/betterde/gonew/internal/cache"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
+)
+
+var bundleOutput string
+
+// bundleCmd represents the template bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <src>",
+	RunE:  bundleTemplate,
+	Args:  cobra.ExactArgs(1),
+	Short: "Pack a template into a file usable by init without network access",
+}
+
+func init() {
+	templateCmd.AddCommand(bundleCmd)
+
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Bundle file to write, defaults to <name>-<version>.tgz")
+}
+
+func bundleTemplate(cmd *cobra.Command, args []string) error {
+	mod, query, ok := strings.Cut(args[0], "@")
+	if !ok {
+		query = "latest"
+	}
+	if err := module.CheckPath(mod); err != nil {
+		return fmt.Errorf("invalid source module name: %v", err)
+	}
+
+	info, err := downloadModule(cmd.Context(), mod+"@"+query)
+	if err != nil {
+		return err
+	}
+
+	output := bundleOutput
+	if output == "" {
+		output = filepath.Base(mod) + "-" + info.Version + ".tgz"
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := bundle.Write(f, mod, info.Version, info.Dir); err != nil {
+		f.Close()
+		os.Remove(output)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("bundled %s@%s into %s", mod, info.Version, output)
+	return nil
+}
+
+// extractBundle verifies and unpacks the bundle at filename into the gonew
+// cache, replacing an earlier extraction, and returns where it is.
+func extractBundle(filename string) (*moduleInfo, error) {
+	index, err := bundle.ReadIndex(filename)
+	if err != nil {
+		return nil, err
+	}
+	root, err := cache.Dir()
+	if err != nil {
+		return nil, err
+	}
+	escaped, err := module.EscapePath(index.Module)
+	if err != nil {
+		return nil, err
+	}
+	escapedVersion, err := module.EscapeVersion(index.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := fileHash(filename)
+	if err != nil {
+		return nil, err
+	}
+	// Other invocations may be reading an extracted bundle, so it is never
+	// replaced: each distinct bundle of a version gets its own directory,
+	// and is extracted beside it and then moved into place in one step.
+	dir := filepath.Join(root, "bundles", filepath.FromSlash(escaped)+"@"+escapedVersion+"-"+sum[:12])
+	if _, err := os.Stat(dir); err == nil {
+		return &moduleInfo{Dir: dir, Version: index.Version}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	// The limits of --max-files and the like apply while extracting, before
+	// anything is written beyond them.
+	extracted := filepath.Join(tmp, "module")
+	if _, err := bundle.Extract(filename, extracted, bundle.Limits(limits)); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(extracted, dir); err != nil {
+		// Another invocation extracting the same bundle got there first.
+		if _, serr := os.Stat(dir); serr != nil {
+			return nil, err
+		}
+	}
+	return &moduleInfo{Dir: dir, Version: index.Version}, nil
+}
+
+// fileHash returns the hex-encoded SHA-256 of the file at filename.
+func fileHash(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
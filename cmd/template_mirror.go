@@ -0,0 +1,148 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/betterde/gonew/internal/git"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
+)
+
+var (
+	mirrorModule string
+	mirrorBranch string
+)
+
+// mirrorCmd represents the template mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror <src> <git-remote>",
+	RunE:  mirrorTemplate,
+	Args:  cobra.ExactArgs(2),
+	Short: "Push a template to an internal git mirror under the mirror's module path",
+}
+
+func init() {
+	templateCmd.AddCommand(mirrorCmd)
+
+	mirrorCmd.Flags().StringVar(&mirrorModule, "module", "", "Module path of the mirror, derived from the remote by default")
+	mirrorCmd.Flags().StringVar(&mirrorBranch, "branch", "main", "Branch of the mirror to commit to")
+}
+
+func mirrorTemplate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	src, remote := args[0], args[1]
+	mod, query, ok := strings.Cut(src, "@")
+	if !ok {
+		query = "latest"
+	}
+	if err := module.CheckPath(mod); err != nil {
+		return fmt.Errorf("invalid source module name: %v", err)
+	}
+
+	newMod := mirrorModule
+	if newMod == "" {
+		derived, err := git.ModulePath(remote)
+		if err != nil {
+			return fmt.Errorf("%v, use --module", err)
+		}
+		// Keep the major version suffix, so the mirror serves the same
+		// major version as the source.
+		if _, major, ok := module.SplitPathVersion(mod); ok && major != "" {
+			derived += major
+		}
+		newMod = derived
+	}
+	if err := module.CheckPath(newMod); err != nil {
+		return fmt.Errorf("invalid mirror module path: %v", err)
+	}
+
+	info, err := downloadModule(ctx, mod+"@"+query)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "gonew-mirror-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.CopyFS(dir, os.DirFS(info.Dir)); err != nil {
+		return err
+	}
+	if _, err := renameModule(dir, mod, newMod); err != nil {
+		return err
+	}
+
+	// Commit on top of the mirror's branch, if it has one, so the mirror
+	// keeps a history of the upstream versions.
+	if _, err := git.Run(ctx, dir, "init", "--quiet"); err != nil {
+		return err
+	}
+	if _, err := git.Run(ctx, dir, "remote", "add", "mirror", remote); err != nil {
+		return err
+	}
+	heads, err := git.Run(ctx, dir, "ls-remote", "--heads", "mirror", mirrorBranch)
+	if err != nil {
+		return err
+	}
+	if heads != "" {
+		if _, err := git.Run(ctx, dir, "fetch", "--quiet", "mirror", mirrorBranch); err != nil {
+			return err
+		}
+		if _, err := git.Run(ctx, dir, "reset", "--soft", "FETCH_HEAD"); err != nil {
+			return err
+		}
+	}
+	if _, err := git.Run(ctx, dir, "add", "-A"); err != nil {
+		return err
+	}
+	if status, err := git.Run(ctx, dir, "status", "--porcelain"); err != nil {
+		return err
+	} else if status == "" {
+		log.Printf("%s already mirrors %s@%s", remote, mod, info.Version)
+		return nil
+	}
+	message := fmt.Sprintf("Mirror %s@%s\n\nModule path rewritten to %s.", mod, info.Version, newMod)
+	if _, err := git.Run(ctx, dir, "commit", "--quiet", "-m", message); err != nil {
+		return err
+	}
+
+	refs := []string{"HEAD:refs/heads/" + mirrorBranch}
+	// Pseudo-versions name commits rather than releases, so only releases
+	// are tagged for the go command to find.
+	if !module.IsPseudoVersion(info.Version) {
+		if _, err := git.Run(ctx, dir, "tag", info.Version); err != nil {
+			return err
+		}
+		refs = append(refs, "refs/tags/"+info.Version)
+	}
+	if _, err := git.Run(ctx, dir, append([]string{"push", "--quiet", "mirror"}, refs...)...); err != nil {
+		return err
+	}
+	log.Printf("mirrored %s@%s to %s as %s", mod, info.Version, remote, newMod)
+	return nil
+}
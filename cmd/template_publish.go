@@ -0,0 +1,213 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/git"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/registry"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/internal/safepath"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+var (
+	publishDir    string
+	publishIndex  string
+	publishRemote string
+	publishNoPush bool
+	publishNotes  string
+)
+
+// publishCmd represents the template publish command
+var publishCmd = &cobra.Command{
+	Use:   "publish <version>",
+	RunE:  publishTemplate,
+	Args:  cobra.ExactArgs(1),
+	Short: "Validate, tag and push a template version and record it in the index",
+}
+
+func init() {
+	templateCmd.AddCommand(publishCmd)
+
+	publishCmd.Flags().StringVar(&publishDir, "dir", ".", "Directory of the template to publish")
+	publishCmd.Flags().StringVar(&publishIndex, "index", os.Getenv("GONEW_INDEX"), "Template index file to update, defaults to $GONEW_INDEX or the configured index")
+	publishCmd.Flags().StringVar(&publishRemote, "remote", "origin", "Git remote to push the tag to")
+	publishCmd.Flags().BoolVar(&publishNoPush, "no-push", false, "Create the tag without pushing it")
+	publishCmd.Flags().StringVar(&publishNotes, "notes", "", "Release notes for the version, prompted for when omitted")
+}
+
+func publishTemplate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	version := args[0]
+	if !semver.IsValid(version) || semver.Canonical(version) != version {
+		return fmt.Errorf("invalid version %s: must be a canonical semantic version such as v1.2.3", version)
+	}
+
+	config, mod, err := validateTemplate(publishDir)
+	if err != nil {
+		return err
+	}
+
+	// Go finds the versions of a module in a subdirectory of its repository
+	// under tags prefixed with that directory.
+	prefix, err := tagPrefix(ctx, publishDir, mod)
+	if err != nil {
+		return err
+	}
+	tag := prefix + version
+
+	status, err := git.Run(ctx, publishDir, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return fmt.Errorf("working tree of %s has uncommitted changes", publishDir)
+	}
+
+	tags, err := git.Run(ctx, publishDir, "tag", "--list", tag)
+	if err != nil {
+		return err
+	}
+	if tags != "" {
+		return fmt.Errorf("tag %s already exists", tag)
+	}
+
+	if !cmd.Flags().Changed("notes") && !noPrompt {
+		prompt := promptui.Prompt{
+			Label: fmt.Sprintf("Release notes for %s (optional)", version),
+		}
+		publishNotes, err = runPrompt(&prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The notes double as the tag annotation so they are visible in git too.
+	message := fmt.Sprintf("%s %s", config.Name, version)
+	if publishNotes != "" {
+		message += "\n\n" + publishNotes
+	}
+	if _, err := git.Run(ctx, publishDir, "tag", "-a", tag, "-m", message); err != nil {
+		return err
+	}
+	log.Printf("tagged %s", tag)
+
+	if !publishNoPush {
+		if _, err := git.Run(ctx, publishDir, "push", publishRemote, tag); err != nil {
+			return err
+		}
+		log.Printf("pushed %s to %s", tag, publishRemote)
+	}
+
+	publishIndex = indexFile(publishIndex)
+	if publishIndex != "" {
+		err := registry.Update(publishIndex, func(index *registry.Index) error {
+			index.Publish(mod, config.Name, config.Desc, &registry.Version{
+				Version:   version,
+				Published: time.Now().UTC(),
+				Notes:     publishNotes,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("recorded %s@%s in %s", mod, version, publishIndex)
+	}
+	return nil
+}
+
+// tagPrefix returns the prefix of the version tags of mod, the module in
+// dir: the path of dir relative to the root of its repository followed by a
+// slash, without the major version subdirectory of a module such as
+// example.com/repo/tmpl/v2 kept in tmpl/v2, and nothing at the root.
+func tagPrefix(ctx context.Context, dir, mod string) (string, error) {
+	top, err := git.Run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	if top, err = safepath.Resolve(top); err != nil {
+		return "", err
+	}
+	resolved, err := safepath.Resolve(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(top, resolved)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if _, major, ok := module.SplitPathVersion(mod); ok && strings.HasPrefix(major, "/") {
+		if rel == major[1:] {
+			rel = "."
+		} else {
+			rel = strings.TrimSuffix(rel, major)
+		}
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return rel + "/", nil
+}
+
+// validateTemplate checks that dir holds a usable template: a valid manifest
+// with a known engine and a go.mod declaring the module path it is published
+// under, which it returns.
+func validateTemplate(dir string) (*project.Config, string, error) {
+	config, err := project.Load(filepath.Join(dir, project.FileName))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, "", err
+	}
+	if config.Name == "" {
+		return nil, "", fmt.Errorf("%s: name is required to publish", project.FileName)
+	}
+	if _, err := render.Lookup(config.Engine); err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, "", err
+	}
+	mod := modfile.ModulePath(data)
+	if mod == "" {
+		return nil, "", fmt.Errorf("%s: no module path in go.mod", dir)
+	}
+	return config, mod, nil
+}
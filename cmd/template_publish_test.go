@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/betterde/gonew/internal/git"
+)
+
+func TestTagPrefix(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	if _, err := git.Run(t.Context(), repo, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir  string
+		mod  string
+		want string
+	}{
+		{".", "example.com/repo", ""},
+		{"templates/service", "example.com/repo/templates/service", "templates/service/"},
+		{"templates/service/v2", "example.com/repo/templates/service/v2", "templates/service/"},
+		{"v2", "example.com/repo/v2", ""},
+		{"tmpl", "example.com/repo/tmpl/v3", "tmpl/"},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join(repo, filepath.FromSlash(tt.dir))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		got, err := tagPrefix(t.Context(), dir, tt.mod)
+		if err != nil || got != tt.want {
+			t.Errorf("tagPrefix(%s, %s) = %q, %v, want %q", tt.dir, tt.mod, got, err, tt.want)
+		}
+	}
+}
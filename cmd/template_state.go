@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stateIndex       string
+	stateReplacement string
+)
+
+// stateCmd represents the template state command
+var stateCmd = &cobra.Command{
+	Use:   "state <module> <experimental|stable|deprecated|blocked>",
+	RunE:  setTemplateState,
+	Args:  cobra.ExactArgs(2),
+	Short: "Set the lifecycle state of a template in the index, overriding its manifest",
+}
+
+func init() {
+	templateCmd.AddCommand(stateCmd)
+
+	stateCmd.Flags().StringVar(&stateIndex, "index", os.Getenv("GONEW_INDEX"), "Template index file to update, defaults to $GONEW_INDEX or the configured index")
+	stateCmd.Flags().StringVar(&stateReplacement, "replacement", "", "Template to suggest instead of a deprecated or blocked one")
+}
+
+func setTemplateState(cmd *cobra.Command, args []string) error {
+	mod, state := args[0], args[1]
+	if state == "" || !project.ValidState(state) {
+		return fmt.Errorf("unknown state %q, must be experimental, stable, deprecated or blocked", state)
+	}
+	stateIndex = indexFile(stateIndex)
+	if stateIndex == "" {
+		return errors.New("no template index configured, use --index, set GONEW_INDEX or set index in the configuration file")
+	}
+
+	err := registry.Update(stateIndex, func(index *registry.Index) error {
+		entry := index.Lookup(mod)
+		if entry == nil {
+			return fmt.Errorf("template %s is not in %s", mod, stateIndex)
+		}
+		entry.State = state
+		entry.Replacement = stateReplacement
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("%s is %s in %s", mod, state, stateIndex)
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/normalize"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsDir     string
+	statsJSON    bool
+	statsLargest int
+)
+
+// statsCmd represents the template stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	RunE:  templateStats,
+	Args:  cobra.NoArgs,
+	Short: "Report the size and complexity of a template",
+}
+
+func init() {
+	templateCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsDir, "dir", ".", "Directory of the template")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the report as JSON")
+	statsCmd.Flags().IntVar(&statsLargest, "largest", 5, "Number of largest files to list")
+}
+
+// Rough time a user spends on each kind of prompt, used for the estimate.
+const (
+	secondsPerVariable = 10
+	secondsPerChoice   = 5
+)
+
+// conditionalPattern matches the opening of conditional blocks of the
+// supported engines: {{ if ... }} and {% if ... %}.
+var conditionalPattern = regexp.MustCompile(`\{\{-?\s*if\s|\{%-?\s*if\s`)
+
+// templateReport is the result of template stats.
+type templateReport struct {
+	Variables          int        `json:"variables"`
+	GeneratedVariables int        `json:"generated_variables"`
+	Features           int        `json:"features"`
+	Layouts            int        `json:"layouts"`
+	Files              int        `json:"files"`
+	TemplatedFiles     int        `json:"templated_files"`
+	RawFiles           int        `json:"raw_files"`
+	ConditionalFiles   int        `json:"conditional_files"`
+	ConditionalBlocks  int        `json:"conditional_blocks"`
+	TotalSize          int64      `json:"total_size"`
+	Largest            []fileSize `json:"largest"`
+	PromptSeconds      int        `json:"prompt_seconds"`
+}
+
+type fileSize struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+func templateStats(cmd *cobra.Command, args []string) error {
+	config, _, err := project.Find(statsDir)
+	if err != nil {
+		return err
+	}
+	root, err := templateRoot(statsDir, config)
+	if err != nil {
+		return err
+	}
+
+	report := &templateReport{
+		Features: len(config.Features),
+		Layouts:  len(config.Layouts),
+	}
+	for _, variable := range config.Variables {
+		if variable.Generated != "" {
+			report.GeneratedVariables++
+			continue
+		}
+		report.Variables++
+	}
+
+	left, _ := config.Delims()
+	var sizes []fileSize
+	err = filepath.WalkDir(root, func(src string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, src)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+
+		report.Files++
+		report.TotalSize += int64(len(data))
+		sizes = append(sizes, fileSize{Path: filepath.ToSlash(rel), Size: int64(len(data))})
+		rendered := !normalize.IsBinaryFile(rel, data) && !glob.MatchAny(config.RenderExclude, filepath.ToSlash(rel))
+		if rendered && (bytes.Contains(data, []byte(left)) || bytes.Contains(data, []byte("{%"))) {
+			report.TemplatedFiles++
+		} else {
+			report.RawFiles++
+		}
+		for _, feature := range config.Features {
+			if glob.MatchAny(feature.Files, filepath.ToSlash(rel)) {
+				report.ConditionalFiles++
+				break
+			}
+		}
+		report.ConditionalBlocks += len(conditionalPattern.FindAllIndex(data, -1))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Size > sizes[j].Size })
+	if len(sizes) > statsLargest {
+		sizes = sizes[:statsLargest]
+	}
+	report.Largest = sizes
+
+	choices := report.Features
+	if report.Layouts > 1 {
+		choices++
+	}
+	report.PromptSeconds = report.Variables*secondsPerVariable + choices*secondsPerChoice
+
+	out := cmd.OutOrStdout()
+	if statsJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "Variables:          %d (%d generated)\n", report.Variables, report.GeneratedVariables)
+	fmt.Fprintf(out, "Features:           %d\n", report.Features)
+	fmt.Fprintf(out, "Layouts:            %d\n", report.Layouts)
+	fmt.Fprintf(out, "Files:              %d (%s)\n", report.Files, formatSize(report.TotalSize))
+	fmt.Fprintf(out, "  templated:        %d\n", report.TemplatedFiles)
+	fmt.Fprintf(out, "  raw:              %d\n", report.RawFiles)
+	fmt.Fprintf(out, "  conditional:      %d\n", report.ConditionalFiles)
+	fmt.Fprintf(out, "Conditional blocks: %d\n", report.ConditionalBlocks)
+	fmt.Fprintf(out, "Est. prompt time:   %s\n", time.Duration(report.PromptSeconds)*time.Second)
+	if len(report.Largest) > 0 {
+		fmt.Fprintln(out, "Largest files:")
+		for _, file := range report.Largest {
+			fmt.Fprintf(out, "  %-40s %s\n", file.Path, formatSize(file.Size))
+		}
+	}
+	return nil
+}
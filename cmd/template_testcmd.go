@@ -0,0 +1,236 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go/version"
+	"log"
+	"os"
+	"os/exec"
+
+	"github.com/betterde/gonew/internal/answers"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/internal/sandbox"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testDir        string
+	testAnswers    []string
+	testGoVersions []string
+	testKeep       bool
+	testModels     string
+)
+
+// testCmd represents the template test command
+var testCmd = &cobra.Command{
+	Use:   "test",
+	RunE:  testTemplate,
+	Args:  cobra.NoArgs,
+	Short: "Generate a project from a local template and build it with each Go version",
+}
+
+func init() {
+	templateCmd.AddCommand(testCmd)
+
+	testCmd.Flags().StringVar(&testDir, "dir", ".", "Directory of the template")
+	testCmd.Flags().StringArrayVar(&testAnswers, "answers", nil, "Read answers from a YAML or JSON file or URL, may be repeated; other variables take their defaults")
+	testCmd.Flags().StringSliceVar(&testGoVersions, "go", nil, "Go versions to build with, overriding go_versions of the template")
+	testCmd.Flags().StringVar(&testModels, "models", "", "Read the models to generate from this YAML file instead of the template's")
+	testCmd.Flags().BoolVar(&failOnWarnings, "fail-on-warnings", false, "Fail if any warning is reported, such as an unused variable or an import left unrewritten")
+	testCmd.Flags().StringSliceVar(&delimiters, "delimiters", nil, "Left and right action delimiters of the template, such as '[[,]]', overriding its delimiters")
+	testCmd.Flags().BoolVar(&testKeep, "keep", false, "Keep the generated project instead of removing it")
+}
+
+func testTemplate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if verifyImage != "" {
+		if err := checkContainerRuntime(); err != nil {
+			return err
+		}
+	}
+	c, err := localComponent(testDir)
+	if err != nil {
+		return err
+	}
+	// Messages and artifacts only matter to init, but templates call them.
+	if _, err := c.load((&summary{}).funcs()); err != nil {
+		return err
+	}
+	if err := checkUnusedVariables(c); err != nil {
+		return err
+	}
+	if err := c.importVariables(ctx); err != nil {
+		return err
+	}
+
+	if testModels != "" {
+		models, err := project.LoadModels(testModels)
+		if err != nil {
+			return err
+		}
+		c.config.Models = models
+	}
+
+	inputs, err := answers.Load(testAnswers...)
+	if err != nil {
+		return err
+	}
+	features := make(map[string]bool, len(c.config.Features))
+	for _, feature := range c.config.Features {
+		features[feature.Name] = feature.Default
+	}
+	inputs, err = defaultAnswers(ctx, c.config, c.config.Migrate(inputs), features)
+	if err != nil {
+		return err
+	}
+	if err := c.config.CheckConstraints(inputs, features); err != nil {
+		return err
+	}
+	if err := c.useLocale(inputs); err != nil {
+		return err
+	}
+	var layout string
+	if len(c.config.Layouts) > 0 {
+		layout = c.config.Layouts[0].Name
+	}
+
+	dir, err := os.MkdirTemp("", "gonew-test-")
+	if err != nil {
+		return err
+	}
+	if testKeep {
+		log.Printf("generating into %s", dir)
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	if err := runHelpers(ctx, []*component{c}, inputs, features); err != nil {
+		return err
+	}
+
+	dstMod = "example.com/gonew/test"
+	files, err := planFiles(ctx, []*component{c}, inputs, features, layout)
+	if err != nil {
+		return err
+	}
+	box, err := sandbox.New(dir, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := writeFiles(ctx, box, files, inputs, features); err != nil {
+		return err
+	}
+
+	versions := c.config.GoVersions
+	if len(testGoVersions) > 0 {
+		versions = testGoVersions
+	}
+	if len(versions) == 0 {
+		// Build with the toolchain at hand.
+		versions = []string{""}
+	}
+
+	failed := 0
+	out := cmd.OutOrStdout()
+	for _, v := range versions {
+		toolchain := toolchainName(v)
+		build := exec.CommandContext(ctx, "go", "build", "./...")
+		build.Dir = dir
+		build.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		if toolchain != "" {
+			build.Env = append(build.Env, "GOTOOLCHAIN="+toolchain)
+		} else {
+			toolchain = "go"
+		}
+		output, err := build.CombinedOutput()
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "%s: FAIL\n%s", toolchain, output)
+			continue
+		}
+		fmt.Fprintf(out, "%s: ok\n", toolchain)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d Go versions failed to build the template", failed, len(versions))
+	}
+	if verifyImage != "" {
+		if err := verifyInContainer(ctx, dir, verifyImage); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: ok\n", verifyImage)
+	}
+	if err := checkWarnings(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// toolchainName returns the GOTOOLCHAIN value selecting Go version v, such
+// as "1.22". Since Go 1.21 releases are numbered from .0, and the bare
+// language version names no toolchain.
+func toolchainName(v string) string {
+	if v == "" {
+		return ""
+	}
+	name := "go" + v
+	if version.Lang(name) == name && version.Compare(name, "go1.21") >= 0 {
+		name += ".0"
+	}
+	return name
+}
+
+// defaultAnswers completes answers without prompting: generated variables
+// are computed and the others take their defaults. Variables without a
+// default need an answer.
+func defaultAnswers(ctx context.Context, config *project.Config, answers map[string]string, features map[string]bool) (map[string]string, error) {
+	for _, variable := range config.Variables {
+		if _, ok := answers[variable.Name]; ok {
+			continue
+		}
+		if asked, err := variable.Asked(config.Vars(answers, features)); err != nil {
+			return nil, err
+		} else if !asked {
+			answers[variable.Name] = ""
+			continue
+		}
+		var value string
+		var err error
+		if variable.Generated != "" {
+			value, err = render.Generate(variable.Name, variable.Generated)
+		} else {
+			value, err = defaultValue(ctx, config, variable, answers, features)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == "" && variable.IsRequired() {
+			return nil, fmt.Errorf("variable %s has no default, answer it with --answers", variable.Name)
+		}
+		answers[variable.Name] = value
+	}
+	return answers, nil
+}
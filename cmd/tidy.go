@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/sandbox"
+)
+
+var noTidy bool
+
+func init() {
+	initCmd.Flags().BoolVar(&noTidy, "no-tidy", false, "Keep the template's go.sum instead of regenerating it with go mod tidy")
+}
+
+// tidyModule regenerates go.sum once go.mod is rewritten for the new module,
+// rather than keeping the template's, whose hashes may be stale or missing
+// and break the first build behind proxies checking them. go mod tidy also
+// drops the requirements the generated project does not use. When it fails,
+// such as offline, the template's go.mod and go.sum are kept with a
+// warning.
+func tidyModule(ctx context.Context, box *sandbox.Sandbox, written map[string]bool) error {
+	if noTidy || !written["go.mod"] || !onlySelected("go.sum") {
+		return nil
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		log.Printf("warning: go not found, keeping the template's go.sum")
+		return nil
+	}
+
+	goMod, err := box.ReadFile("go.mod")
+	if err != nil {
+		return err
+	}
+	goSum, err := box.ReadFile("go.sum")
+	hadSum := err == nil
+	if hadSum {
+		if err := box.Remove("go.sum"); err != nil {
+			return err
+		}
+	}
+
+	tidy := exec.CommandContext(ctx, "go", "mod", "tidy")
+	tidy.Dir = box.Root()
+	output := io.MultiWriter(os.Stderr, &outputEvents{})
+	tidy.Stdout = output
+	tidy.Stderr = output
+	if err := tidy.Run(); err != nil {
+		log.Printf("warning: go mod tidy: %v, keeping the template's go.mod and go.sum", err)
+		if err := box.WriteFile("go.mod", goMod, 0666); err != nil {
+			return err
+		}
+		if hadSum {
+			return box.WriteFile("go.sum", goSum, 0666)
+		}
+		return nil
+	}
+
+	// Write what tidy produced through the sandbox, so the audit log
+	// records it like every other generated file.
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(box.Root(), name))
+		if os.IsNotExist(err) {
+			// Without requirements there is no go.sum.
+			delete(written, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := box.WriteFile(name, data, 0666); err != nil {
+			return err
+		}
+		written[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/normalize"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	treeDepth  int
+	treeJSON   bool
+	treeLayout string
+)
+
+// treeCmd represents the tree command
+var treeCmd = &cobra.Command{
+	Use:   "tree <src>",
+	RunE:  printTree,
+	Args:  cobra.ExactArgs(1),
+	Short: "Print the file tree a template would generate",
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().IntVar(&treeDepth, "depth", 0, "Maximum depth to print, 0 means unlimited")
+	treeCmd.Flags().BoolVar(&treeJSON, "json", false, "Print the tree as JSON")
+	treeCmd.Flags().StringVar(&treeLayout, "layout", "", "Directory layout to print, defaults to the template's first")
+}
+
+// treeNode is a file or directory of a template.
+type treeNode struct {
+	Name        string      `json:"name"`
+	Dir         bool        `json:"dir"`
+	Size        int64       `json:"size"`
+	Files       int         `json:"files,omitempty"`
+	Templated   bool        `json:"templated,omitempty"`
+	Conditional bool        `json:"conditional,omitempty"`
+	Children    []*treeNode `json:"children,omitempty"`
+}
+
+func printTree(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	c, err := parseSource(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	info, err := c.source.resolve(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	// Without a readable manifest no file is known to be conditional.
+	config, _, err := project.Find(info.Dir)
+	if err != nil {
+		config = &project.Config{}
+	}
+	dir, err := templateRoot(info.Dir, config)
+	if err != nil {
+		return err
+	}
+
+	// Without --layout, the tree is the one init generates without prompts.
+	var layout *project.Layout
+	if treeLayout != "" {
+		if layout = config.Layout(treeLayout); layout == nil {
+			return fmt.Errorf("template has no layout %s", treeLayout)
+		}
+	} else if len(config.Layouts) > 0 {
+		layout = &config.Layouts[0]
+	}
+
+	root, err := buildTree(info.Dir, dir, config, layout)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if treeJSON {
+		pruneTree(root, treeDepth)
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(root); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	version := info.Version
+	if version == "" {
+		version = args[0]
+	}
+	fmt.Fprintf(out, "%s (%d files, %s)\n", version, root.Files, formatSize(root.Size))
+	writeTree(out, root, "", 1, treeDepth)
+	return nil
+}
+
+// buildTree walks root, the template root of the module in dir, and returns
+// the tree of the files init generates from it with layout, at the paths
+// they are written to, with sizes and file counts aggregated into each
+// directory. Files left out whatever the answers are skipped, and files that
+// depend on a feature or a files condition are marked conditional, as are
+// the directories holding only such files.
+func buildTree(dir, root string, config *project.Config, layout *project.Layout) (*treeNode, error) {
+	left, _ := config.Delims()
+	nodes := map[string]*treeNode{".": {Name: ".", Dir: true}}
+	var dirNode func(rel string) *treeNode
+	dirNode = func(rel string) *treeNode {
+		if node, ok := nodes[rel]; ok {
+			return node
+		}
+		node := &treeNode{Name: filepath.Base(rel), Dir: true, Conditional: true}
+		parent := dirNode(filepath.Dir(rel))
+		parent.Children = append(parent.Children, node)
+		nodes[rel] = node
+		return node
+	}
+	add := func(rel string, conditional bool, data []byte) {
+		dst := dstPath(config, layout, rel)
+		node := &treeNode{Name: filepath.Base(dst), Size: int64(len(data)), Conditional: conditional}
+		node.Templated = bytes.Contains(data, []byte(left)) && !normalize.IsBinaryFile(rel, data) && !glob.MatchAny(config.RenderExclude, filepath.ToSlash(rel))
+		parent := dirNode(filepath.Dir(dst))
+		parent.Children = append(parent.Children, node)
+
+		// Account for the file in every enclosing directory.
+		for p := filepath.Dir(dst); ; p = filepath.Dir(p) {
+			nodes[p].Files++
+			nodes[p].Size += node.Size
+			if p == "." {
+				break
+			}
+			if !conditional {
+				nodes[p].Conditional = false
+			}
+		}
+	}
+
+	// conditional holds the template directories depending on a feature
+	// or a files condition.
+	conditional := make(map[string]bool)
+	err := filepath.WalkDir(root, func(src string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, src)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		inherited := conditional[filepath.Dir(rel)] || config.Conditional(filepath.ToSlash(rel))
+		if d.IsDir() {
+			conditional[rel] = inherited
+			return nil
+		}
+		if ignoredFile(config, filepath.ToSlash(rel)) {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("refusing to read template file %s: not a regular file", filepath.ToSlash(rel))
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		add(rel, inherited, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range inheritedFiles(config, dir, root) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		add(name, false, data)
+	}
+	sortTree(nodes["."])
+	return nodes["."], nil
+}
+
+// sortTree sorts the children below node by name, as mapping paths to
+// their destinations may have added them out of order.
+func sortTree(node *treeNode) {
+	slices.SortFunc(node.Children, func(a, b *treeNode) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, child := range node.Children {
+		sortTree(child)
+	}
+}
+
+// pruneTree drops the children below depth, 0 means unlimited.
+func pruneTree(node *treeNode, depth int) {
+	if depth <= 0 {
+		return
+	}
+	if depth == 1 {
+		for _, child := range node.Children {
+			child.Children = nil
+		}
+		return
+	}
+	for _, child := range node.Children {
+		pruneTree(child, depth-1)
+	}
+}
+
+// writeTree prints the children of node with box-drawing prefixes.
+func writeTree(w io.Writer, node *treeNode, prefix string, level, depth int) {
+	for i, child := range node.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if child.Dir {
+			var mark string
+			if child.Conditional {
+				mark = " [conditional]"
+			}
+			fmt.Fprintf(w, "%s%s%s/ (%d files, %s)%s\n", prefix, branch, child.Name, child.Files, formatSize(child.Size), mark)
+			if depth <= 0 || level < depth {
+				writeTree(w, child, prefix+indent, level+1, depth)
+			}
+			continue
+		}
+
+		var marks string
+		if child.Templated {
+			marks += " [templated]"
+		}
+		if child.Conditional {
+			marks += " [conditional]"
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)%s\n", prefix, branch, child.Name, formatSize(child.Size), marks)
+	}
+}
+
+// formatSize formats n bytes using binary units.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
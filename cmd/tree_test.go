@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/betterde/gonew/internal/project"
+)
+
+func TestBuildTree(t *testing.T) {
+	c := testComponent(t, map[string]string{
+		"go.mod":                  "module example.com/service\n",
+		"src/main.go":             "package main // {{ .Name }}\n",
+		"src/TEMPLATE_README.md":  "Run make\n",
+		"src/.gonew/hooks.sh":     "exit 0\n",
+		"src/snippets/header.txt": "header\n",
+		"src/db/db.go":            "package db\n",
+		"src/metrics/metrics.go":  "package metrics\n",
+	})
+	config := &project.Config{
+		Root:     "src",
+		Ignore:   []string{"snippets/**"},
+		Features: []project.Feature{{Name: "metrics", Files: []string{"metrics/**"}}},
+		Files:    []project.FileCondition{{Glob: "db", When: "Database"}},
+	}
+	root, err := templateRoot(c.root, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := buildTree(c.root, root, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	writeTree(&out, tree, "", 1, 0)
+	got := out.String()
+	for _, want := range []string{
+		"db/ (1 files, 11 B) [conditional]",
+		"db.go (11 B) [conditional]",
+		"metrics.go (16 B) [conditional]",
+		"main.go (28 B) [templated]",
+		"go.mod (27 B)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("tree misses %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"TEMPLATE_README.md", ".gonew", "snippets", "src"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("tree lists %q, which init does not generate:\n%s", unwanted, got)
+		}
+	}
+	if tree.Files != 4 {
+		t.Errorf("tree counts %d files, want 4", tree.Files)
+	}
+}
+
+func TestBuildTreeRefusesSymlinks(t *testing.T) {
+	c := testComponent(t, map[string]string{"main.go": "package main\n"})
+	if err := os.Symlink(filepath.Join(c.root, "main.go"), filepath.Join(c.root, "link.go")); err != nil {
+		t.Skip(err)
+	}
+	if _, err := buildTree(c.root, c.root, &project.Config{}, nil); err == nil {
+		t.Error("buildTree accepted a symlink")
+	}
+}
+
+func TestBuildTreeDestinations(t *testing.T) {
+	c := testComponent(t, map[string]string{
+		"gitignore":                "bin/\n",
+		"_github/workflows/ci.yml": "on: push\n",
+		"pkg/api/api.go":           "package api\n",
+		"pkg/db/db.go":             "package db\n",
+	})
+	config := &project.Config{
+		Restore: map[string]string{"gitignore": ".gitignore", "_github": ".github"},
+		Files:   []project.FileCondition{{Glob: "pkg/db", When: "Database"}},
+		Layouts: []project.Layout{{Name: "flat", Paths: map[string]string{"pkg/": "internal/"}}},
+	}
+	tree, err := buildTree(c.root, c.root, config, config.Layout("flat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	writeTree(&out, tree, "", 1, 0)
+	want := `├── .github/ (1 files, 9 B)
+│   └── workflows/ (1 files, 9 B)
+│       └── ci.yml (9 B)
+├── .gitignore (5 B)
+└── internal/ (2 files, 23 B)
+    ├── api/ (1 files, 12 B)
+    │   └── api.go (12 B)
+    └── db/ (1 files, 11 B) [conditional]
+        └── db.go (11 B) [conditional]
+`
+	if got := out.String(); got != want {
+		t.Errorf("tree =\n%s\nwant\n%s", got, want)
+	}
+}
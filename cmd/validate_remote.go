@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betterde/gonew/internal/project"
+)
+
+// defaultRemoteTimeout bounds remote validations declaring no timeout.
+const defaultRemoteTimeout = 5 * time.Second
+
+var (
+	remoteMu    sync.Mutex
+	remoteCache = make(map[string]error)
+)
+
+// checkRemote checks value against the validate_remote endpoint of variable.
+// Results are cached for the run, so answers checked once, such as in a
+// form submitted again, are not requested twice. An endpoint that cannot be
+// reached, or answers with another status such as 401, is reported and the answer accepted, so an outage does
+// not block generating projects.
+func checkRemote(ctx context.Context, variable project.Variable, value string) error {
+	remote := variable.ValidateRemote
+	if remote == nil || value == "" {
+		return nil
+	}
+	method := strings.ToUpper(remote.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	target := strings.ReplaceAll(remote.URL, "{value}", url.PathEscape(value))
+	key := method + " " + target
+
+	remoteMu.Lock()
+	err, ok := remoteCache[key]
+	remoteMu.Unlock()
+	if ok {
+		return err
+	}
+
+	timeout := defaultRemoteTimeout
+	if remote.Timeout != "" {
+		if timeout, err = time.ParseDuration(remote.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("validate_remote: invalid timeout %q", remote.Timeout)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range remote.Headers {
+		req.Header.Set(name, expandRemoteEnv(variable.Name, name, value))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("%s: could not validate %q: %v", variable.Name, value, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		err = nil
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusGone, http.StatusUnprocessableEntity:
+		err = fmt.Errorf("%q is not known to %s (%s)", value, req.URL.Host, resp.Status)
+	default:
+		log.Printf("%s: could not validate %q: %s answered %s", variable.Name, value, req.URL.Host, resp.Status)
+		return nil
+	}
+
+	remoteMu.Lock()
+	remoteCache[key] = err
+	remoteMu.Unlock()
+	return err
+}
+
+// expandRemoteEnv expands the environment variables in the value of header,
+// declared by the validate_remote of variable. Only the variables listed in
+// remote_env of the configuration file are expanded, so a template cannot
+// send secrets such as GITHUB_TOKEN to an endpoint of its choosing; others
+// are reported and left empty.
+func expandRemoteEnv(variable, header, value string) string {
+	return os.Expand(value, func(name string) string {
+		if slices.Contains(userSettings.RemoteEnv, name) {
+			return os.Getenv(name)
+		}
+		log.Printf("warning: %s: validate_remote header %s refers to $%s, which is not listed in remote_env of the configuration file; it is left empty", variable, header, name)
+		return ""
+	})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/settings"
+)
+
+func TestCheckRemoteHeaderEnv(t *testing.T) {
+	t.Setenv("GONEW_TEST_ALLOWED", "allowed")
+	t.Setenv("GONEW_TEST_SECRET", "secret")
+	saved := userSettings
+	userSettings = &settings.Settings{RemoteEnv: []string{"GONEW_TEST_ALLOWED"}}
+	defer func() { userSettings = saved }()
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	variable := project.Variable{
+		Name: "Team",
+		ValidateRemote: &project.RemoteValidation{
+			URL: server.URL + "/teams/{value}",
+			Headers: map[string]string{
+				"X-Allowed": "$GONEW_TEST_ALLOWED",
+				"X-Secret":  "Bearer ${GONEW_TEST_SECRET}",
+			},
+		},
+	}
+	if err := checkRemote(context.Background(), variable, "platform"); err != nil {
+		t.Fatal(err)
+	}
+	if v := got.Get("X-Allowed"); v != "allowed" {
+		t.Errorf("X-Allowed = %q, want %q", v, "allowed")
+	}
+	if v := got.Get("X-Secret"); v != "Bearer" {
+		t.Errorf("X-Secret = %q, want the variable left out", v)
+	}
+}
+
+func TestRemoteValidationTimeout(t *testing.T) {
+	for _, timeout := range []string{"soon", "0s", "-1s"} {
+		r := project.RemoteValidation{URL: "https://example.com/{value}", Timeout: timeout}
+		if err := r.Check(); err == nil {
+			t.Errorf("Check accepted timeout %q", timeout)
+		}
+	}
+	r := project.RemoteValidation{URL: "https://example.com/{value}", Timeout: "3s"}
+	if err := r.Check(); err != nil {
+		t.Errorf("Check rejected timeout 3s: %v", err)
+	}
+}
+
+func TestRunPromptsChecksGivenAnswers(t *testing.T) {
+	defer func(v bool) { noPrompt = v }(noPrompt)
+	noPrompt = true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/teams/platform" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	optional := false
+	config := &project.Config{Variables: []project.Variable{
+		{Name: "Name"},
+		{Name: "Port", Type: "int", Required: &optional},
+		{Name: "Team", ValidateRemote: &project.RemoteValidation{URL: server.URL + "/teams/{value}"}},
+		{Name: "Region", When: "Team == \"cloud\""},
+	}}
+	valid := map[string]string{"Name": "app", "Port": "", "Team": "platform"}
+
+	if _, err := runPrompts(t.Context(), config, valid, nil); err != nil {
+		t.Fatalf("runPrompts rejected valid answers: %v", err)
+	}
+	for name, answer := range map[string]string{"Name": "", "Port": "http", "Team": "unknown"} {
+		answers := maps.Clone(valid)
+		answers[name] = answer
+		if _, err := runPrompts(t.Context(), config, answers, nil); err == nil {
+			t.Errorf("runPrompts accepted %s=%q", name, answer)
+		}
+	}
+}
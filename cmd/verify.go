@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// verifyImage, set by --verify-in-container, is the image, such as
+// golang:1.23, in which the generated project is built and tested.
+var verifyImage string
+
+// verifyEnv are the variables of the go command passed on to the
+// container when they are set, so that private modules can be fetched.
+var verifyEnv = []string{"GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOFLAGS"}
+
+// verifyScript builds and tests a copy of the project mounted read-only at
+// /src, so that nothing the go command writes reaches the host.
+const verifyScript = "cp -R /src /work && cd /work && go build ./... && go test ./..."
+
+func init() {
+	initCmd.Flags().StringVar(&verifyImage, "verify-in-container", "", "Build and test the generated project in a container of this image, such as golang:1.23, to check it does not depend on host tools")
+	testCmd.Flags().StringVar(&verifyImage, "verify-in-container", "", "Also build and test the generated project in a container of this image, such as golang:1.23")
+}
+
+// containerRuntime returns the command running containers, docker unless
+// $GONEW_CONTAINER_RUNTIME names another, such as podman.
+func containerRuntime() string {
+	if runtime := os.Getenv("GONEW_CONTAINER_RUNTIME"); runtime != "" {
+		return runtime
+	}
+	return "docker"
+}
+
+// checkContainerRuntime reports a missing container runtime before the
+// project is generated rather than once it is.
+func checkContainerRuntime() error {
+	runtime := containerRuntime()
+	if _, err := exec.LookPath(runtime); err != nil {
+		return fmt.Errorf("--verify-in-container needs %s: %v", runtime, err)
+	}
+	return nil
+}
+
+// verifyInContainer builds and tests the project in dir in a container of
+// image, with its output on stderr and sent as output events.
+func verifyInContainer(ctx context.Context, dir, image string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	args := []string{"run", "--rm", "-v", abs + ":/src:ro"}
+	for _, name := range verifyEnv {
+		if os.Getenv(name) != "" {
+			args = append(args, "-e", name)
+		}
+	}
+	args = append(args, image, "sh", "-c", verifyScript)
+	log.Printf("verifying the project in %s", image)
+
+	process := exec.CommandContext(ctx, containerRuntime(), args...)
+	output := io.MultiWriter(os.Stderr, &outputEvents{})
+	process.Stdout = output
+	process.Stderr = output
+	if err := process.Run(); err != nil {
+		return fmt.Errorf("project does not build and pass its tests in %s: %v", image, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/betterde/gonew/internal/build"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	RunE:  printVersion,
+	Args:  cobra.NoArgs,
+	Short: "Show the version of gonew and the template schema it supports",
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print the version as JSON")
+}
+
+// versionInfo is the output of version --json, for tools checking that
+// gonew supports their templates.
+type versionInfo struct {
+	build.Info
+	// SchemaVersion is the latest manifest schema supported.
+	SchemaVersion int `json:"schema_version"`
+}
+
+func printVersion(cmd *cobra.Command, args []string) error {
+	info := versionInfo{Info: build.Current(), SchemaVersion: project.SchemaVersion}
+	out := cmd.OutOrStdout()
+	if versionJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(info); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "%s %s\n", build.Name, info.Version)
+	if info.Commit != "" {
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+		fmt.Fprintf(out, "commit:   %s%s\n", info.Commit, modified)
+	}
+	if info.Date != "" {
+		fmt.Fprintf(out, "built:    %s\n", info.Date)
+	}
+	fmt.Fprintf(out, "go:       %s %s\n", info.GoVersion, info.Platform)
+	fmt.Fprintf(out, "schema:   %d\n", info.SchemaVersion)
+	return nil
+}
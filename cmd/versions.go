@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/betterde/gonew/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var versionsIndex string
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions <src>",
+	RunE:  listVersions,
+	Args:  cobra.ExactArgs(1),
+	Short: "List the published versions of a template with their release notes",
+}
+
+func init() {
+	rootCmd.AddCommand(versionsCmd)
+
+	versionsCmd.Flags().StringVar(&versionsIndex, "index", os.Getenv("GONEW_INDEX"), "Template index file or URL, defaults to $GONEW_INDEX or the configured index")
+}
+
+func listVersions(cmd *cobra.Command, args []string) error {
+	versionsIndex = indexFile(versionsIndex)
+	if versionsIndex == "" {
+		return errors.New("no template index configured, use --index, set GONEW_INDEX or set index in the configuration file")
+	}
+
+	index, err := registry.Open(versionsIndex)
+	if err != nil {
+		return err
+	}
+	entry := index.Lookup(args[0])
+	if entry == nil {
+		return fmt.Errorf("template %s is not in %s", args[0], versionsIndex)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, version := range entry.Versions {
+		fmt.Fprintf(out, "%s (%s)\n", version.Version, version.Published.Format("2006-01-02"))
+		if version.Notes != "" {
+			fmt.Fprintf(out, "  %s\n", version.Notes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright © 2025 George <george@betterde.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/betterde/gonew/internal/normalize"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/pkg/progress"
+	"gopkg.in/yaml.v3"
+)
+
+// Warning codes. They are stable, so that CI jobs can look for them in the
+// JSON summary or the events.
+const (
+	// warnUnusedVariable: a variable no file, path or manifest entry uses.
+	warnUnusedVariable = "W001"
+	// W012, a binary file holding template delimiters being rendered, is
+	// no longer reported: binary files are copied without rendering.
+	// warnImportNotRewritten: a generated Go file or go.mod still refers
+	// to the template's module path.
+	warnImportNotRewritten = "W020"
+	// warnBadgeSkipped: a badge of the composed README does not apply to
+	// the module path of the project.
+	warnBadgeSkipped = "W030"
+)
+
+// warningSeverity is the severity of each warning code.
+var warningSeverity = map[string]string{
+	warnUnusedVariable:     "low",
+	warnImportNotRewritten: "high",
+	warnBadgeSkipped:       "low",
+}
+
+// failOnWarnings makes init and template test fail once done when any
+// warning was reported.
+var failOnWarnings bool
+
+// warning is a problem with a template or the generated project that does
+// not stop the generation.
+type warning struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	File     string `json:"file,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (w warning) String() string {
+	if w.File == "" {
+		return fmt.Sprintf("warning %s: %s", w.Code, w.Message)
+	}
+	return fmt.Sprintf("warning %s: %s: %s", w.Code, w.File, w.Message)
+}
+
+var (
+	warningsMu sync.Mutex
+	warnings   []warning
+)
+
+// warn reports a warning with code about file, which may be empty. A
+// warning already reported is not reported again.
+func warn(code, file, format string, args ...any) {
+	w := warning{
+		Code:     code,
+		Severity: warningSeverity[code],
+		File:     filepath.ToSlash(file),
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	warningsMu.Lock()
+	for _, reported := range warnings {
+		if reported == w {
+			warningsMu.Unlock()
+			return
+		}
+	}
+	warnings = append(warnings, w)
+	warningsMu.Unlock()
+
+	log.Print(w)
+	emit(progress.Event{Kind: progress.KindWarning, Time: time.Now(), Code: w.Code, Path: w.File, Text: w.Message})
+}
+
+// reportedWarnings returns the warnings reported so far.
+func reportedWarnings() []warning {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	return append([]warning(nil), warnings...)
+}
+
+// checkWarnings returns an error under --fail-on-warnings when warnings were
+// reported.
+func checkWarnings() error {
+	if n := len(reportedWarnings()); n > 0 && failOnWarnings {
+		return fmt.Errorf("%d warnings reported, failing because of --fail-on-warnings", n)
+	}
+	return nil
+}
+
+// checkUnusedVariables warns about the variables of c's manifest that
+// none of its files, file names or other manifest entries refer to. A
+// variable counts as used wherever its name appears as a word, so the check
+// only reports variables that are certainly unused.
+func checkUnusedVariables(c *component) error {
+	if len(c.config.Variables) == 0 {
+		return nil
+	}
+
+	// The manifest is searched without the declarations of the variables,
+	// which name them all, but with the defaults that may refer to others.
+	config := *c.config
+	config.Variables = nil
+	manifest, err := yaml.Marshal(&config)
+	if err != nil {
+		return err
+	}
+	texts := [][]byte{manifest}
+	for _, variable := range c.config.Variables {
+		texts = append(texts, []byte(variable.Default), []byte(variable.Generated))
+	}
+
+	err = filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		texts = append(texts, []byte(filepath.ToSlash(rel)))
+		if d.IsDir() || !d.Type().IsRegular() || rel == project.FileName {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !normalize.IsBinary(data) {
+			texts = append(texts, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range c.config.Variables {
+		word := regexp.MustCompile(`\b` + regexp.QuoteMeta(variable.Name) + `\b`)
+		used := false
+		for _, text := range texts {
+			if word.Match(text) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			warn(warnUnusedVariable, project.FileName, "%s: variable %s is not used", c, variable.Name)
+		}
+	}
+	return nil
+}
+
+// checkImports warns when the generated Go source in data, of the file at
+// name, still imports packages of srcMod. Sources that do not parse are
+// not checked.
+func checkImports(name string, data []byte, srcMod string) {
+	f, err := parser.ParseFile(token.NewFileSet(), name, data, parser.ImportsOnly)
+	if err != nil {
+		return
+	}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if path == srcMod || strings.HasPrefix(path, srcMod+"/") {
+			warn(warnImportNotRewritten, name, "import %s was not rewritten to the destination module", path)
+		}
+	}
+}
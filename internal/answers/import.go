@@ -0,0 +1,63 @@
+// Package answers reads variable answers from files written by gonew and
+// other scaffolding tools.
+package answers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Import reads the answers recorded by another scaffolding tool in an
+// existing project. It understands copier answer files (.copier-answers.yml),
+// whose underscore-prefixed keys are copier metadata, and yeoman's .yo-rc.json,
+// which stores answers per generator.
+func Import(filename string) (map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Base(filename) == ".yo-rc.json" {
+		return importYeoman(filename, data)
+	}
+	return importCopier(filename, data)
+}
+
+func importCopier(filename string, data []byte) (map[string]string, error) {
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	answers := make(map[string]string, len(values))
+	for name, value := range values {
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		answers[name] = fmt.Sprint(value)
+	}
+	return answers, nil
+}
+
+func importYeoman(filename string, data []byte) (map[string]string, error) {
+	var generators map[string]map[string]any
+	if err := json.Unmarshal(data, &generators); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(generators) != 1 {
+		return nil, fmt.Errorf("%s: expected answers for exactly one generator, found %d", filename, len(generators))
+	}
+
+	answers := make(map[string]string)
+	for _, values := range generators {
+		for name, value := range values {
+			answers[name] = fmt.Sprint(value)
+		}
+	}
+	return answers, nil
+}
@@ -0,0 +1,105 @@
+package answers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenEnv names the environment variable holding the bearer token sent
+// when fetching answer files over HTTPS.
+const TokenEnv = "GONEW_ANSWERS_TOKEN"
+
+// Load reads gonew answer files, YAML or JSON mappings of variable names to
+// answers, from paths or http(s) URLs, and deep-merges them in order: a later file overrides the values of
+// an earlier one, and nested mappings are merged key by key rather than
+// replaced. Nested mappings and lists become the JSON encoding of their value,
+// and underscore-prefixed keys are ignored.
+func Load(filenames ...string) (map[string]string, error) {
+	merged := make(map[string]any)
+	for _, filename := range filenames {
+		data, err := read(filename)
+		if err != nil {
+			return nil, err
+		}
+		var values map[string]any
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		Merge(merged, values)
+	}
+
+	answers := make(map[string]string, len(merged))
+	for name, value := range merged {
+		// Underscore-prefixed keys are metadata, as in the files Save writes.
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("answer %s: %v", name, err)
+			}
+			answers[name] = string(data)
+		case nil:
+			answers[name] = ""
+		default:
+			answers[name] = fmt.Sprint(value)
+		}
+	}
+	return answers, nil
+}
+
+// read returns the content of the answer file at source, a path or an
+// http(s) URL. The token of TokenEnv is only sent over HTTPS.
+func read(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return os.ReadFile(source)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv(TokenEnv); token != "" && req.URL.Scheme == "https" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize))
+}
+
+// maxRemoteSize bounds the size of answer files fetched over HTTP.
+const maxRemoteSize = 1 << 20
+
+// Merge deep-merges src into dst. Mappings present in both are merged
+// recursively, any other value of src replaces the one in dst.
+func Merge(dst, src map[string]any) {
+	for key, value := range src {
+		from, ok := value.(map[string]any)
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		into, ok := dst[key].(map[string]any)
+		if !ok {
+			into = make(map[string]any, len(from))
+			dst[key] = into
+		}
+		Merge(into, from)
+	}
+}
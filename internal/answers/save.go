@@ -0,0 +1,69 @@
+package answers
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the answers file gonew writes into generated projects, meant to
+// be committed so that teammates reuse the same answers.
+const FileName = "gonew.answers.yaml"
+
+// Record is what a project was generated with.
+type Record struct {
+	// Template lists the templates applied with their versions, as in
+	// "example.com/base@v1.2.0+example.com/grpc@v0.3.1".
+	Template string
+	Features []string
+	Layout   string
+	Answers  map[string]string
+}
+
+// Marshal encodes r as an answers file that Load reads back: the answers
+// sorted by name, after underscore-prefixed metadata keys.
+func Marshal(r *Record) ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value *yaml.Node) {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	scalar := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: quoteStyle(value)}
+	}
+
+	add("_template", scalar(r.Template))
+	if len(r.Features) > 0 {
+		features := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, feature := range r.Features {
+			features.Content = append(features.Content, scalar(feature))
+		}
+		add("_features", features)
+	}
+	if r.Layout != "" {
+		add("_layout", scalar(r.Layout))
+	}
+
+	names := make([]string, 0, len(r.Answers))
+	for name := range r.Answers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, scalar(r.Answers[name]))
+	}
+	doc.HeadComment = "Answers this project was generated with by gonew, reuse them with --answers."
+	return yaml.Marshal(doc)
+}
+
+// quoteStyle quotes values YAML would otherwise read as another type, such
+// as "8080" or "true", so they come back as the same strings.
+func quoteStyle(value string) yaml.Style {
+	var decoded any
+	if err := yaml.Unmarshal([]byte(value), &decoded); err != nil {
+		return yaml.DoubleQuotedStyle
+	}
+	if s, ok := decoded.(string); ok && s == value {
+		return 0
+	}
+	return yaml.DoubleQuotedStyle
+}
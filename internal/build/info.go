@@ -1,7 +1,59 @@
 package build
 
+import (
+	"runtime"
+	"runtime/debug"
+)
+
 var (
 	Name    = "gonew"
 	Desc    = "A scaffolding tool for generating new projects using project templates"
 	Version = "v1.1.0"
+	// Commit and Date describe the source the binary was built from. They
+	// are set with -ldflags "-X", and otherwise read from the version
+	// control information the go command stamps into the binary, where the
+	// date is that of the commit.
+	Commit = ""
+	Date   = ""
 )
+
+// Info describes the running binary.
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	Date    string `json:"date,omitempty"`
+	// Modified reports uncommitted changes in the source built from.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Current returns the information about the running binary.
+func Current() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
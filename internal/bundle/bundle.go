@@ -0,0 +1,273 @@
+// Package bundle packs a template module into a single gzipped tar file, so
+// that it can be carried into networks without access to a module proxy.
+// The bundle starts with an index recording the module, its version and the
+// SHA-256 checksum of every file, which extraction verifies. The checksums
+// travel in the bundle itself, so they detect corruption, such as a
+// truncated copy, but not tampering: whoever can change a file can change its
+// checksum too.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/betterde/gonew/internal/safepath"
+)
+
+// IndexName is the name of the index, the first entry of a bundle.
+const IndexName = "gonew-bundle.json"
+
+// filesDir is the directory of the bundle holding the module files.
+const filesDir = "files/"
+
+// Index describes the content of a bundle.
+type Index struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	// Files maps the slash-separated path of every file of the module to
+	// the hex SHA-256 checksum of its content.
+	Files map[string]string `json:"files"`
+}
+
+// Limits bound what extracting a bundle may write, checked as it is read so
+// a crafted bundle cannot fill the disk or memory first. Zero values mean
+// the defaults.
+type Limits struct {
+	MaxFiles     int
+	MaxTotalSize int64
+	MaxFileSize  int64
+}
+
+// Default limits, generous for templates while keeping extraction bounded.
+const (
+	DefaultMaxFiles     = 10000
+	DefaultMaxTotalSize = 512 << 20
+	// maxIndexSize bounds the index, whatever the limits.
+	maxIndexSize = 16 << 20
+)
+
+// withDefaults returns l with the defaults for its zero values. A file is
+// never larger than the whole bundle.
+func (l Limits) withDefaults() Limits {
+	if l.MaxFiles <= 0 {
+		l.MaxFiles = DefaultMaxFiles
+	}
+	if l.MaxTotalSize <= 0 {
+		l.MaxTotalSize = DefaultMaxTotalSize
+	}
+	if l.MaxFileSize <= 0 || l.MaxFileSize > l.MaxTotalSize {
+		l.MaxFileSize = l.MaxTotalSize
+	}
+	return l
+}
+
+// IsBundle reports whether source names a bundle rather than a module.
+func IsBundle(source string) bool {
+	return strings.HasSuffix(source, ".tgz") || strings.HasSuffix(source, ".tar.gz")
+}
+
+// Write packs the module mod at version, extracted in dir, into w.
+func Write(w io.Writer, mod, version, dir string) error {
+	index := &Index{Module: mod, Version: version, Files: make(map[string]string)}
+	var names []string
+	err := filepath.WalkDir(dir, func(src string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s: not a regular file", src)
+		}
+		rel, err := filepath.Rel(dir, src)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		sum := sha256.Sum256(data)
+		index.Files[name] = hex.EncodeToString(sum[:])
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(tw, IndexName, data); err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(tw, filesDir+name, data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// ReadIndex returns the index of the bundle at filename.
+func ReadIndex(filename string) (*Index, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tr, err := open(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	index, err := readIndex(tr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return index, nil
+}
+
+// Extract unpacks the bundle at filename into dir, which must not exist,
+// and returns its index. It fails if a file is missing, unlisted or does not
+// match its checksum, or if the bundle exceeds limits.
+func Extract(filename, dir string, limits Limits) (*Index, error) {
+	limits = limits.withDefaults()
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tr, err := open(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	index, err := readIndex(tr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(index.Files) > limits.MaxFiles {
+		return nil, fmt.Errorf("%s: bundle has more than %d files", filename, limits.MaxFiles)
+	}
+	if err := os.Mkdir(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(index.Files))
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			// Directories are created for the files inside them.
+			continue
+		}
+		name, ok := strings.CutPrefix(header.Name, filesDir)
+		want, listed := index.Files[name]
+		if !ok || !listed || seen[name] || header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%s: unexpected entry %s", filename, header.Name)
+		}
+		if err := safepath.CheckRel(filepath.FromSlash(name)); err != nil || path.Clean(name) != name {
+			return nil, fmt.Errorf("%s: unsafe entry %s", filename, header.Name)
+		}
+		seen[name] = true
+
+		// The sizes in headers are only trusted to fail early, reading
+		// stops at the limits whatever they claim.
+		if header.Size > limits.MaxFileSize {
+			return nil, fmt.Errorf("%s: %s is larger than %d bytes", filename, name, limits.MaxFileSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, min(limits.MaxFileSize, limits.MaxTotalSize-total)+1))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		total += int64(len(data))
+		switch {
+		case int64(len(data)) > limits.MaxFileSize:
+			return nil, fmt.Errorf("%s: %s is larger than %d bytes", filename, name, limits.MaxFileSize)
+		case total > limits.MaxTotalSize:
+			return nil, fmt.Errorf("%s: bundle is larger than %d bytes", filename, limits.MaxTotalSize)
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("%s: checksum mismatch for %s", filename, name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range index.Files {
+		if !seen[name] {
+			return nil, fmt.Errorf("%s: missing %s", filename, name)
+		}
+	}
+	return index, nil
+}
+
+func open(r io.Reader) (*tar.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return tar.NewReader(gz), nil
+}
+
+func readIndex(tr *tar.Reader) (*Index, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != IndexName {
+		return nil, errors.New("not a gonew bundle")
+	}
+	index := &Index{}
+	if err := json.NewDecoder(io.LimitReader(tr, maxIndexSize)).Decode(index); err != nil {
+		return nil, fmt.Errorf("%s: %v", IndexName, err)
+	}
+	if index.Module == "" || index.Version == "" {
+		return nil, fmt.Errorf("%s: module and version are required", IndexName)
+	}
+	return index, nil
+}
@@ -0,0 +1,123 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeBundle writes a bundle of files, given as names and contents, whose
+// index lists the checksums of listed, or of files if listed is nil.
+func writeBundle(t *testing.T, files map[string]string, listed map[string]string) string {
+	t.Helper()
+	if listed == nil {
+		listed = files
+	}
+	index := &Index{Module: "example.com/template", Version: "v1.0.0", Files: make(map[string]string)}
+	for name, content := range listed {
+		sum := sha256.Sum256([]byte(content))
+		index.Files[name] = hex.EncodeToString(sum[:])
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeEntry(tw, IndexName, data); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := writeEntry(tw, filesDir+name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "template.tgz")
+	if err := os.WriteFile(filename, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestWriteExtract(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{"go.mod": "module example.com/template\n", "cmd/main.go": "package main\n"}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, "example.com/template", "v1.0.0", src); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "template.tgz")
+	if err := os.WriteFile(filename, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "out")
+	index, err := Extract(filename, dir, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Module != "example.com/template" || index.Version != "v1.0.0" || len(index.Files) != len(files) {
+		t.Errorf("Extract index = %+v", index)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil || string(data) != content {
+			t.Errorf("extracted %s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+
+	if _, err := Extract(filename, dir, Limits{}); err == nil {
+		t.Errorf("Extract into an existing directory succeeded")
+	}
+}
+
+func TestExtractRefuses(t *testing.T) {
+	large := strings.Repeat("x", 1000)
+	tests := []struct {
+		name   string
+		files  map[string]string
+		listed map[string]string
+		limits Limits
+		want   string
+	}{
+		{"too many files", map[string]string{"a": "", "b": "", "c": ""}, nil, Limits{MaxFiles: 2}, "more than 2 files"},
+		{"large file", map[string]string{"a": large}, nil, Limits{MaxFileSize: 999}, "larger than 999 bytes"},
+		{"large bundle", map[string]string{"a": large, "b": large}, nil, Limits{MaxTotalSize: 1500}, "larger than 1500 bytes"},
+		{"escaping entry", map[string]string{"../a": ""}, nil, Limits{}, "unsafe entry"},
+		{"unlisted entry", map[string]string{"a": "", "b": ""}, map[string]string{"a": ""}, Limits{}, "unexpected entry"},
+		{"missing entry", map[string]string{"a": ""}, map[string]string{"a": "", "b": ""}, Limits{}, "missing b"},
+		{"checksum mismatch", map[string]string{"a": "changed"}, map[string]string{"a": "original"}, Limits{}, "checksum mismatch"},
+	}
+	for _, tt := range tests {
+		filename := writeBundle(t, tt.files, tt.listed)
+		_, err := Extract(filename, filepath.Join(t.TempDir(), "out"), tt.limits)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Extract = %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package cache stores template manifests locally so they can be consulted
+// before the template itself is downloaded.
+package cache
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/lockfile"
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/betterde/gonew/internal/project"
+	"golang.org/x/mod/module"
+)
+
+// Dir returns the root directory of the gonew cache.
+func Dir() (string, error) {
+	return paths.Cache()
+}
+
+// manifestPath returns the location of the cached manifest for mod at version.
+// The version may be a query such as "latest".
+func manifestPath(mod, version string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", err
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "manifests", filepath.FromSlash(escaped), escapedVersion+".yaml"), nil
+}
+
+// LoadManifest returns the cached manifest for mod at version.
+// It returns an error satisfying os.IsNotExist if nothing is cached.
+func LoadManifest(mod, version string) (*project.Config, error) {
+	filename, err := manifestPath(mod, version)
+	if err != nil {
+		return nil, err
+	}
+	return project.Load(filename)
+}
+
+// SaveManifest stores the raw manifest data for mod at each of the given
+// versions. Each file is replaced atomically, as other invocations may be
+// reading it.
+func SaveManifest(mod string, data []byte, versions ...string) error {
+	for _, version := range versions {
+		filename, err := manifestPath(mod, version)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+		if err := lockfile.WriteFile(filename, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package catalog registers generated services in service catalogs, such as
+// Backstage or OpsLevel, through providers registered by name.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the Backstage descriptor generated in the project.
+const FileName = "catalog-info.yaml"
+
+// Entity describes a service to a catalog.
+type Entity struct {
+	Name        string
+	Description string
+	Owner       string
+	Type        string
+	Lifecycle   string
+	System      string
+}
+
+// Marshal returns the entity as a Backstage Component descriptor.
+func (e Entity) Marshal() ([]byte, error) {
+	type metadata struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description,omitempty"`
+	}
+	type spec struct {
+		Type      string `yaml:"type"`
+		Lifecycle string `yaml:"lifecycle"`
+		Owner     string `yaml:"owner"`
+		System    string `yaml:"system,omitempty"`
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	err := encoder.Encode(struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Kind       string   `yaml:"kind"`
+		Metadata   metadata `yaml:"metadata"`
+		Spec       spec     `yaml:"spec"`
+	}{
+		APIVersion: "backstage.io/v1alpha1",
+		Kind:       "Component",
+		Metadata:   metadata{Name: e.Name, Description: e.Description},
+		Spec:       spec{Type: e.Type, Lifecycle: e.Lifecycle, Owner: e.Owner, System: e.System},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Registration is a request to add an entity to a catalog.
+type Registration struct {
+	Entity Entity
+	// URL is the catalog's API endpoint; providers may have a default.
+	URL string
+	// Target is where the catalog reads the descriptor from, such as the
+	// URL of catalog-info.yaml in the service's repository.
+	Target string
+	Token  string
+}
+
+// A Provider registers entities in one kind of catalog, returning a link to
+// the registered entity when the catalog reports one.
+type Provider interface {
+	Register(ctx context.Context, client *http.Client, r Registration) (string, error)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{
+		"backstage": backstage{},
+		"opslevel":  opslevel{},
+	}
+)
+
+// RegisterProvider makes p available under name, replacing any provider
+// registered before under the same name.
+func RegisterProvider(name string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[name] = p
+}
+
+// Lookup returns the provider registered under name.
+func Lookup(name string) (Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown catalog provider %q, available providers: %v", name, names())
+	}
+	return p, nil
+}
+
+func names() []string {
+	list := make([]string, 0, len(providers))
+	for name := range providers {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// backstage registers the descriptor at the registration's target as a
+// location of a Backstage catalog, whose base URL is the registration's URL.
+type backstage struct{}
+
+func (backstage) Register(ctx context.Context, client *http.Client, r Registration) (string, error) {
+	if r.URL == "" {
+		return "", errors.New("backstage: no catalog url")
+	}
+	if r.Target == "" {
+		return "", errors.New("backstage: no target, the URL Backstage reads catalog-info.yaml from")
+	}
+	body, err := json.Marshal(map[string]string{"type": "url", "target": r.Target})
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(r.URL, "/")
+	if _, err := post(ctx, client, base+"/api/catalog/locations", body, r.Token); err != nil {
+		return "", fmt.Errorf("backstage: %v", err)
+	}
+	return base + "/catalog/default/component/" + r.Entity.Name, nil
+}
+
+// opslevelURL is the OpsLevel GraphQL API, used when no URL is set.
+const opslevelURL = "https://app.opslevel.com/graphql"
+
+// opslevel creates the entity as an OpsLevel service.
+type opslevel struct{}
+
+func (opslevel) Register(ctx context.Context, client *http.Client, r Registration) (string, error) {
+	url := r.URL
+	if url == "" {
+		url = opslevelURL
+	}
+	input := map[string]any{"name": r.Entity.Name}
+	if r.Entity.Description != "" {
+		input["description"] = r.Entity.Description
+	}
+	if r.Entity.Owner != "" {
+		input["ownerInput"] = map[string]string{"alias": r.Entity.Owner}
+	}
+	if r.Entity.Lifecycle != "" {
+		input["lifecycleAlias"] = r.Entity.Lifecycle
+	}
+	body, err := json.Marshal(map[string]any{
+		"query":     "mutation($input: ServiceCreateInput!) { serviceCreate(input: $input) { service { htmlUrl } errors { message } } }",
+		"variables": map[string]any{"input": input},
+	})
+	if err != nil {
+		return "", err
+	}
+	data, err := post(ctx, client, url, body, r.Token)
+	if err != nil {
+		return "", fmt.Errorf("opslevel: %v", err)
+	}
+
+	var resp struct {
+		Data struct {
+			ServiceCreate struct {
+				Service struct {
+					HTMLURL string `json:"htmlUrl"`
+				} `json:"service"`
+				Errors []struct {
+					Message string `json:"message"`
+				} `json:"errors"`
+			} `json:"serviceCreate"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("opslevel: invalid response: %v", err)
+	}
+	errs := append(resp.Errors, resp.Data.ServiceCreate.Errors...)
+	if len(errs) > 0 {
+		return "", fmt.Errorf("opslevel: %s", errs[0].Message)
+	}
+	return resp.Data.ServiceCreate.Service.HTMLURL, nil
+}
+
+// post sends body as JSON to url and returns the response body of a
+// successful request.
+func post(ctx context.Context, client *http.Client, url string, body []byte, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s: %s: %s", url, resp.Status, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
@@ -0,0 +1,97 @@
+// Package cloud suggests variable defaults from the cloud context of the
+// developer's machine: the current Kubernetes context, AWS profile and
+// GCP configuration.
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timeout bounds each CLI call, so a slow or misconfigured tool only
+// costs a default rather than hanging the prompts.
+const timeout = 5 * time.Second
+
+type provider func(ctx context.Context) (string, error)
+
+var providers = map[string]provider{
+	"cloud.kube.context": func(ctx context.Context) (string, error) {
+		return run(ctx, "kubectl", "config", "current-context")
+	},
+	"cloud.kube.namespace": func(ctx context.Context) (string, error) {
+		return run(ctx, "kubectl", "config", "view", "--minify", "--output", "jsonpath={..namespace}")
+	},
+	"cloud.aws.region": func(ctx context.Context) (string, error) {
+		if region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"); region != "" {
+			return region, nil
+		}
+		return run(ctx, "aws", "configure", "get", "region")
+	},
+	"cloud.aws.account": func(ctx context.Context) (string, error) {
+		return run(ctx, "aws", "sts", "get-caller-identity", "--query", "Account", "--output", "text")
+	},
+	"cloud.gcp.project": func(ctx context.Context) (string, error) {
+		if project := firstEnv("CLOUDSDK_CORE_PROJECT", "GOOGLE_CLOUD_PROJECT"); project != "" {
+			return project, nil
+		}
+		return run(ctx, "gcloud", "config", "get-value", "project")
+	},
+}
+
+// Valid reports whether key names a known provider.
+func Valid(key string) bool {
+	_, ok := providers[key]
+	return ok
+}
+
+// Keys returns the known provider keys in sorted order.
+func Keys() []string {
+	keys := make([]string, 0, len(providers))
+	for key := range providers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Lookup returns the value of the provider key, such as "cloud.aws.region".
+// Canceling ctx stops the CLI it runs.
+func Lookup(ctx context.Context, key string) (string, error) {
+	p, ok := providers[key]
+	if !ok {
+		return "", fmt.Errorf("unknown cloud provider key %s", key)
+	}
+	value, err := p(ctx)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	return value, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,166 @@
+// Package editorconfig reads .editorconfig files and applies the properties
+// that can be enforced on generated text: indentation, trailing whitespace,
+// final newlines and line endings.
+package editorconfig
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/normalize"
+)
+
+// FileName is the name of the file holding EditorConfig rules.
+const FileName = ".editorconfig"
+
+type section struct {
+	patterns   []string
+	properties map[string]string
+}
+
+// Config is a parsed .editorconfig file.
+type Config struct {
+	sections []section
+}
+
+// Parse reads the sections of an .editorconfig file. Properties outside any
+// section, such as root, are ignored.
+func Parse(data []byte) *Config {
+	config := &Config{}
+	var current *section
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			config.sections = append(config.sections, section{
+				patterns:   expandBraces(line[1 : len(line)-1]),
+				properties: make(map[string]string),
+			})
+			current = &config.sections[len(config.sections)-1]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		current.properties[strings.ToLower(strings.TrimSpace(key))] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return config
+}
+
+// Properties returns the properties applying to name, a slash-separated path
+// relative to the directory of the .editorconfig file. Later sections win.
+func (c *Config) Properties(name string) map[string]string {
+	properties := make(map[string]string)
+	for _, s := range c.sections {
+		if !glob.MatchAny(s.patterns, name) {
+			continue
+		}
+		for key, value := range s.properties {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
+// Apply rewrites data according to the properties applying to name.
+func (c *Config) Apply(name string, data []byte) []byte {
+	properties := c.Properties(name)
+	if len(properties) == 0 {
+		return data
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		cr := strings.HasSuffix(line, "\r")
+		line = strings.TrimSuffix(line, "\r")
+		if properties["trim_trailing_whitespace"] == "true" {
+			line = strings.TrimRight(line, " \t")
+		}
+		line = indent(line, properties)
+		if cr {
+			line += "\r"
+		}
+		lines[i] = line
+	}
+	data = []byte(strings.Join(lines, "\n"))
+
+	switch properties["end_of_line"] {
+	case "lf":
+		data = normalize.LineEndings(data, "lf")
+	case "crlf":
+		data = normalize.LineEndings(data, "crlf")
+	}
+
+	switch properties["insert_final_newline"] {
+	case "true":
+		if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+			newline := "\n"
+			if properties["end_of_line"] == "crlf" {
+				newline = "\r\n"
+			}
+			data = append(data, newline...)
+		}
+	case "false":
+		data = bytes.TrimRight(data, "\r\n")
+	}
+	return data
+}
+
+// indent converts the leading whitespace of line to the configured indent style.
+func indent(line string, properties map[string]string) string {
+	size, err := strconv.Atoi(properties["indent_size"])
+	if err != nil || size <= 0 {
+		size = 4
+	}
+
+	body := strings.TrimLeft(line, " \t")
+	lead := line[:len(line)-len(body)]
+	if lead == "" {
+		return line
+	}
+
+	// Measure the indentation in columns, with tabs at the indent size.
+	width := 0
+	for _, r := range lead {
+		if r == '\t' {
+			width += size
+		} else {
+			width++
+		}
+	}
+
+	switch properties["indent_style"] {
+	case "space":
+		return strings.Repeat(" ", width) + body
+	case "tab":
+		return strings.Repeat("\t", width/size) + strings.Repeat(" ", width%size) + body
+	}
+	return line
+}
+
+// expandBraces expands the {a,b} alternatives of an EditorConfig glob into
+// plain glob patterns.
+func expandBraces(pattern string) []string {
+	open := strings.Index(pattern, "{")
+	if open < 0 {
+		return []string{pattern}
+	}
+	end := strings.Index(pattern[open:], "}")
+	if end < 0 {
+		return []string{pattern}
+	}
+	end += open
+
+	var patterns []string
+	for _, alternative := range strings.Split(pattern[open+1:end], ",") {
+		patterns = append(patterns, expandBraces(pattern[:open]+alternative+pattern[end+1:])...)
+	}
+	return patterns
+}
@@ -0,0 +1,378 @@
+// Package expr evaluates the boolean expressions of template manifests, such
+// as "PortHTTP != PortGRPC && Replicas <= MaxReplicas".
+//
+// Expressions compare variables, string literals in double or single quotes,
+// numbers and true or false with ==, !=, <, <=, > and >=, and combine
+// conditions with &&, || and !, grouped by parentheses. Operands that are
+// both numbers, or strings holding numbers, compare numerically; others
+// compare as strings.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Vars looks up the value of a variable, a string, bool or number.
+type Vars func(name string) (any, bool)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses the expression src.
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", src, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the variables of vars.
+func (e *Expr) Eval(vars Vars) (any, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", e.src, err)
+	}
+	return v, nil
+}
+
+// Bool evaluates the expression as a condition.
+func (e *Expr) Bool(vars Vars) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+type token struct {
+	kind string // "op", "ident", "string" or "number"
+	text string
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "&&") || strings.HasPrefix(src[i:], "||") ||
+			strings.HasPrefix(src[i:], "==") || strings.HasPrefix(src[i:], "!=") ||
+			strings.HasPrefix(src[i:], "<=") || strings.HasPrefix(src[i:], ">="):
+			tokens = append(tokens, token{"op", src[i : i+2]})
+			i += 2
+		case strings.ContainsRune("()!<>", rune(c)):
+			tokens = append(tokens, token{"op", src[i : i+1]})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			text := src[i+1 : j]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(src[i : j+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string %s", src[i:j+1])
+				}
+				text = unquoted
+			}
+			tokens = append(tokens, token{"string", text})
+			i = j + 1
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(src[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %s", src[i:j])
+			}
+			tokens = append(tokens, token{"number", src[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{"ident", src[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == "op" && p.tokens[p.pos].text == op
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("||") {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("&&") {
+		p.pos++
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.peek("!") {
+		p.pos++
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return negation{operand}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.peek(op) {
+			p.pos++
+			right, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			return comparison{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "string":
+		return literal{t.text}, nil
+	case "number":
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return literal{f}, nil
+	case "ident":
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		}
+		return variable{t.text}, nil
+	}
+	if t.text == "(" {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t.text)
+}
+
+type node interface {
+	eval(vars Vars) (any, error)
+}
+
+type literal struct{ value any }
+
+func (n literal) eval(Vars) (any, error) { return n.value, nil }
+
+type variable struct{ name string }
+
+func (n variable) eval(vars Vars) (any, error) {
+	v, ok := vars(n.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %s", n.name)
+	}
+	return v, nil
+}
+
+type negation struct{ operand node }
+
+func (n negation) eval(vars Vars) (any, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n logical) eval(vars Vars) (any, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	// Like Go, the right operand is only evaluated when needed.
+	if truthy(left) == (n.op == "||") {
+		return truthy(left), nil
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type comparison struct {
+	op          string
+	left, right node
+}
+
+func (n comparison) eval(vars Vars) (any, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var c int
+	a, aNum := number(left)
+	b, bNum := number(right)
+	switch {
+	case aNum && bNum:
+		c = compare(a, b)
+	default:
+		if lb, ok := left.(bool); ok {
+			left = strconv.FormatBool(lb)
+		}
+		if rb, ok := right.(bool); ok {
+			right = strconv.FormatBool(rb)
+		}
+		c = strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+	}
+
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// number returns v as a number, if it is one or a string holding one.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// truthy reports whether v holds as a condition: true, a non-zero number,
+// or a string other than empty or a false boolean.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+		return v != ""
+	case nil:
+		return false
+	}
+	if f, ok := number(v); ok {
+		return f != 0
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []token
+	}{
+		{"A == 'b'", []token{{"ident", "A"}, {"op", "=="}, {"string", "b"}}},
+		{`Name != "a\"b"`, []token{{"ident", "Name"}, {"op", "!="}, {"string", `a"b`}}},
+		{"!(x<=-1.5)||Features.db", []token{{"op", "!"}, {"op", "("}, {"ident", "x"}, {"op", "<="}, {"number", "-1.5"}, {"op", ")"}, {"op", "||"}, {"ident", "Features.db"}}},
+		{"a>=1&&b>2", []token{{"ident", "a"}, {"op", ">="}, {"number", "1"}, {"op", "&&"}, {"ident", "b"}, {"op", ">"}, {"number", "2"}}},
+		{" \t\n", nil},
+	}
+	for _, tt := range tests {
+		got, err := lex(tt.src)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lex(%q) = %v, %v, want %v", tt.src, got, err, tt.want)
+		}
+	}
+	for _, src := range []string{`"open`, "a = b", "1.2.3", "a # b", `"\q"`} {
+		if got, err := lex(src); err == nil {
+			t.Errorf("lex(%q) = %v, want error", src, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, src := range []string{"a", "a == b", "!a && (b || c)", "a < 1 || b >= 'x'", "((a))"} {
+		if _, err := Parse(src); err != nil {
+			t.Errorf("Parse(%q): %v", src, err)
+		}
+	}
+	for _, src := range []string{"", "a ==", "(a", "a b", "&& a", "a == == b", ")"} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", src)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	values := map[string]any{
+		"Enabled":  true,
+		"Disabled": false,
+		"Port":     8080,
+		"Other":    "8081",
+		"Ratio":    0.5,
+		"Name":     "api",
+		"Empty":    "",
+		"Off":      "false",
+	}
+	vars := func(name string) (any, bool) {
+		v, ok := values[name]
+		return v, ok
+	}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"Enabled", true},
+		{"Enabled == true", true},
+		{"Disabled == false", true},
+		{"Disabled != true", true},
+		{"!Enabled", false},
+		{"Port == 8080", true},
+		{"Port < Other", true},
+		{"Port != Other && Ratio <= 0.5", true},
+		{"Other == 8081.0", true},
+		{"Name == 'api'", true},
+		{"Name > 'abc'", true},
+		{"Empty", false},
+		{"Off", false},
+		{"Off == false", true},
+		{"Name", true},
+		{"Disabled || Port > 9000", false},
+		{"!(Disabled || Port > 9000)", true},
+		// The right operand is not evaluated when the left one decides.
+		{"Enabled || Missing", true},
+		{"Disabled && Missing", false},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		got, err := e.Bool(vars)
+		if err != nil || got != tt.want {
+			t.Errorf("%s = %v, %v, want %v", tt.src, got, err, tt.want)
+		}
+	}
+	for _, src := range []string{"Missing", "Enabled && Missing == 1"} {
+		e, err := Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := e.Bool(vars); err == nil {
+			t.Errorf("%s = %v, want an unknown variable error", src, got)
+		}
+	}
+}
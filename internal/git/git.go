@@ -0,0 +1,85 @@
+// Package git runs the git command line tool.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Run runs git with args in dir and returns its trimmed standard output.
+// Git is killed if ctx is done before it exits.
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return RunEnv(ctx, dir, nil, args...)
+}
+
+// RunEnv is like Run with env added to the environment, such as
+// GIT_INDEX_FILE to work on an index of its own.
+func RunEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Dir = dir
+	if env != nil {
+		command.Env = append(os.Environ(), env...)
+	}
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, stderr.Bytes())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// ModulePath derives a Go module path such as github.com/org/repo from a
+// remote URL in any of the forms git accepts: https://host/org/repo.git,
+// ssh://git@host/org/repo.git or git@host:org/repo.git.
+func ModulePath(remote string) (string, error) {
+	rest := remote
+	if _, after, ok := strings.Cut(rest, "://"); ok {
+		rest = after
+	} else if at := strings.Index(rest, "@"); at >= 0 && strings.Contains(rest[at:], ":") {
+		// scp-like syntax: user@host:path
+		rest = strings.Replace(rest[at+1:], ":", "/", 1)
+	}
+
+	// Drop credentials and ports from the host.
+	if at := strings.LastIndex(rest, "@"); at >= 0 {
+		rest = rest[at+1:]
+	}
+	host, repo, ok := strings.Cut(rest, "/")
+	if !ok || host == "" || repo == "" {
+		return "", fmt.Errorf("cannot derive a module path from remote %s", remote)
+	}
+	host, _, _ = strings.Cut(host, ":")
+
+	repo = strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+	return host + "/" + repo, nil
+}
+
+// RemoteModulePath returns the module path derived from the origin remote of
+// the repository containing dir, or from its first remote if there is no origin.
+func RemoteModulePath(ctx context.Context, dir string) (string, error) {
+	remotes, err := Run(ctx, dir, "remote")
+	if err != nil {
+		return "", err
+	}
+	names := strings.Fields(remotes)
+	if len(names) == 0 {
+		return "", fmt.Errorf("repository has no remotes")
+	}
+	name := names[0]
+	for _, n := range names {
+		if n == "origin" {
+			name = n
+		}
+	}
+
+	remote, err := Run(ctx, dir, "remote", "get-url", name)
+	if err != nil {
+		return "", err
+	}
+	return ModulePath(remote)
+}
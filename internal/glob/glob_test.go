@@ -0,0 +1,52 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/db/db.go", true},
+		{"*.go", "main.go.tmpl", false},
+		{"metrics/**", "metrics", true},
+		{"metrics/**", "metrics/metrics.go", true},
+		{"metrics/**", "metrics/prom/handler.go", true},
+		{"metrics/**", "internal/metrics/metrics.go", false},
+		{"**/metrics/*.go", "metrics/metrics.go", true},
+		{"**/metrics/*.go", "internal/metrics/metrics.go", true},
+		{"**/metrics/*.go", "internal/metrics/prom/handler.go", false},
+		{"cmd/**/main.go", "cmd/main.go", true},
+		{"cmd/**/main.go", "cmd/server/api/main.go", true},
+		{"cmd/**/main.go", "cmd/server/main_test.go", false},
+		{"**", "any/path/at/all", true},
+		{"**/*_test.go", "db_test.go", true},
+		{"internal/*/db.go", "internal/store/db.go", true},
+		{"internal/*/db.go", "internal/store/sql/db.go", false},
+		{"deploy/k8s/*.yaml", "deploy/k8s/service.yaml", true},
+		{"deploy/k8s/*.yaml", "deploy/k8s", false},
+		{"deploy/[a-c]*/x", "deploy/base/x", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"*.md", "docs/**"}
+	for name, want := range map[string]bool{
+		"README.md":       true,
+		"docs/api/ref.go": true,
+		"cmd/main.go":     false,
+	} {
+		if got := MatchAny(patterns, name); got != want {
+			t.Errorf("MatchAny(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if MatchAny(nil, "main.go") {
+		t.Error("MatchAny without patterns matched")
+	}
+}
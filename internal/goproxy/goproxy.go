@@ -0,0 +1,97 @@
+// Package goproxy inspects the module proxies listed in GOPROXY, to explain
+// which of them failed to serve a template and why.
+package goproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Special GOPROXY entries.
+const (
+	Direct = "direct"
+	Off    = "off"
+)
+
+// Parse splits a GOPROXY value into its entries, in the order the go command
+// tries them, whether they are separated by "," or "|".
+func Parse(list string) []string {
+	var entries []string
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '|' }) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// IsHTTP reports whether entry is a proxy served over HTTP or HTTPS.
+func IsHTTP(entry string) bool {
+	return strings.HasPrefix(entry, "https://") || strings.HasPrefix(entry, "http://")
+}
+
+// Probe asks the HTTP proxy at base about the module query mod@query and
+// returns the status of its response: the version's .info for a canonical
+// version, the latest version for "latest", and the version list otherwise.
+func Probe(ctx context.Context, client *http.Client, base, mod, query string) (string, error) {
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", err
+	}
+	endpoint := "/@v/list"
+	switch {
+	case query == "latest":
+		endpoint = "/@latest"
+	case semver.IsValid(query) && semver.Canonical(query) == query:
+		v, err := module.EscapeVersion(query)
+		if err != nil {
+			return "", err
+		}
+		endpoint = "/@v/" + v + ".info"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+"/"+escaped+endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.Status, nil
+}
+
+// ChecksumFailure reports whether a go command error message is about the
+// checksum database failing to verify a module, as it does for private
+// modules it cannot see.
+func ChecksumFailure(msg string) bool {
+	for _, s := range []string{"verifying module", "verifying go.mod", "SECURITY ERROR", "checksum mismatch", "sumdb"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Attempt is the outcome of downloading from one GOPROXY entry.
+type Attempt struct {
+	Proxy string
+	// Status is the HTTP status of the proxy's response, if it was probed.
+	Status string
+	Err    error
+}
+
+func (a Attempt) String() string {
+	if a.Status != "" {
+		return fmt.Sprintf("%s: %s: %v", a.Proxy, a.Status, a.Err)
+	}
+	return fmt.Sprintf("%s: %v", a.Proxy, a.Err)
+}
@@ -0,0 +1,197 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ErrNoRange is returned by OpenZip when the proxy does not serve byte
+// ranges of module zips, which then have to be downloaded whole.
+var ErrNoRange = errors.New("proxy does not serve byte ranges")
+
+// rangeBlock is the least a range request asks for, so that reading the
+// zip directory entry by entry takes few requests.
+const rangeBlock = 64 << 10
+
+// Resolve asks the HTTP proxy at base for the version the module query
+// mod@query stands for. Like the go command, it picks the latest version
+// from the version list of proxies without an @latest endpoint.
+func Resolve(ctx context.Context, client *http.Client, base, mod, query string) (string, error) {
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return "", err
+	}
+	prefix := strings.TrimSuffix(base, "/") + "/" + escaped
+	endpoint := "/@latest"
+	if query != "latest" {
+		v, err := module.EscapeVersion(query)
+		if err != nil {
+			return "", err
+		}
+		endpoint = "/@v/" + v + ".info"
+	}
+	data, err := get(ctx, client, prefix+endpoint)
+	if errors.Is(err, errNotFound) && query == "latest" {
+		data, err = get(ctx, client, prefix+"/@v/list")
+		if err != nil {
+			return "", err
+		}
+		return latest(strings.Fields(string(data)), prefix)
+	}
+	if err != nil {
+		return "", err
+	}
+	var info struct{ Version string }
+	if err := json.Unmarshal(data, &info); err != nil {
+		return "", fmt.Errorf("%s: %v", prefix+endpoint, err)
+	}
+	if info.Version == "" {
+		return "", fmt.Errorf("%s: no version", prefix+endpoint)
+	}
+	return info.Version, nil
+}
+
+// latest returns the latest release of versions, or their latest
+// pre-release if there is no release.
+func latest(versions []string, prefix string) (string, error) {
+	var release, prerelease string
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			if release == "" || semver.Compare(v, release) > 0 {
+				release = v
+			}
+		} else if prerelease == "" || semver.Compare(v, prerelease) > 0 {
+			prerelease = v
+		}
+	}
+	switch {
+	case release != "":
+		return release, nil
+	case prerelease != "":
+		return prerelease, nil
+	}
+	return "", fmt.Errorf("%s/@v/list: no versions", prefix)
+}
+
+// errNotFound is returned by get for the 404 and 410 responses proxies give
+// for what they do not serve.
+var errNotFound = errors.New("not found")
+
+// get returns the body of a successful response to a GET of url.
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%s: %s: %w", url, resp.Status, errNotFound)
+	default:
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// OpenZip opens the zip of mod@version served by the HTTP proxy at base
+// without downloading it: its files are read with range requests, so that
+// reading one of them costs a few small requests. It returns ErrNoRange if
+// the proxy serves the zip whole.
+func OpenZip(ctx context.Context, client *http.Client, base, mod, version string) (*zip.Reader, error) {
+	escaped, err := module.EscapePath(mod)
+	if err != nil {
+		return nil, err
+	}
+	v, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	r := &rangeReader{ctx: ctx, client: client, url: strings.TrimSuffix(base, "/") + "/" + escaped + "/@v/" + v + ".zip"}
+	if r.size, err = r.fetch(0, 0); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(r, r.size)
+}
+
+// rangeReader reads a file served over HTTP with range requests, keeping
+// the last range read.
+type rangeReader struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+	// block holds the bytes of the file from off.
+	off   int64
+	block []byte
+}
+
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		if pos < r.off || pos >= r.off+int64(len(r.block)) {
+			end := min(pos+int64(max(len(p)-n, rangeBlock)), r.size) - 1
+			if _, err := r.fetch(pos, end); err != nil {
+				return n, err
+			}
+		}
+		n += copy(p[n:], r.block[pos-r.off:])
+	}
+	return n, nil
+}
+
+// fetch reads the bytes from start to end, inclusive, into block and
+// returns the size of the file.
+func (r *rangeReader) fetch(start, end int64) (int64, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return 0, ErrNoRange
+	default:
+		return 0, fmt.Errorf("%s: %s", r.url, resp.Status)
+	}
+
+	var first, last, size int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &first, &last, &size); err != nil || first != start || last != end {
+		return 0, fmt.Errorf("%s: unexpected Content-Range %q", r.url, resp.Header.Get("Content-Range"))
+	}
+	block, err := io.ReadAll(io.LimitReader(resp.Body, end-start+2))
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(block)) != end-start+1 {
+		return 0, fmt.Errorf("%s: got %d bytes of range %d-%d", r.url, len(block), start, end)
+	}
+	r.off, r.block = start, block
+	return size, nil
+}
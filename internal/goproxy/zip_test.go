@@ -0,0 +1,125 @@
+package goproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testZip returns a module zip of example.com/tmpl@v1.0.0 with a manifest
+// and a large incompressible file.
+func testZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := map[string][]byte{
+		"template.yaml": []byte("name: tmpl\n"),
+		"go.mod":        []byte("module example.com/tmpl\n"),
+		"large.bin":     make([]byte, 1<<20),
+	}
+	rand.New(rand.NewSource(1)).Read(files["large.bin"])
+	for name, data := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: "example.com/tmpl@v1.0.0/" + name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testProxy serves data as the zip of example.com/tmpl@v1.0.0, with range
+// requests if ranges is set, and counts the bytes it sends.
+func testProxy(t *testing.T, data []byte, ranges bool) (*httptest.Server, *int64) {
+	var sent int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/tmpl/@latest", "/example.com/tmpl/@v/v1.0.0.info":
+			io.WriteString(w, `{"Version":"v1.0.0"}`)
+		case "/example.com/listed/@v/list":
+			io.WriteString(w, "v1.0.0\nv1.2.0\nv1.10.0-rc.1\nv1.1.0\n")
+		case "/example.com/prerelease/@v/list":
+			io.WriteString(w, "v0.1.0-alpha\nv0.2.0-beta\n")
+		case "/example.com/tmpl/@v/v1.0.0.zip":
+			counter := &countingWriter{ResponseWriter: w, n: &sent}
+			if !ranges {
+				r.Header.Del("Range")
+			}
+			http.ServeContent(counter, r, "", time.Time{}, bytes.NewReader(data))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &sent
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	n *int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w.n += int64(len(p))
+	return w.ResponseWriter.Write(p)
+}
+
+func TestResolve(t *testing.T) {
+	srv, _ := testProxy(t, nil, true)
+	for _, query := range []string{"latest", "v1.0.0"} {
+		if v, err := Resolve(t.Context(), srv.Client(), srv.URL, "example.com/tmpl", query); err != nil || v != "v1.0.0" {
+			t.Errorf("Resolve(%s) = %s, %v, want v1.0.0", query, v, err)
+		}
+	}
+	for mod, want := range map[string]string{"example.com/listed": "v1.2.0", "example.com/prerelease": "v0.2.0-beta"} {
+		if v, err := Resolve(t.Context(), srv.Client(), srv.URL, mod, "latest"); err != nil || v != want {
+			t.Errorf("Resolve(%s@latest) from the version list = %s, %v, want %s", mod, v, err, want)
+		}
+	}
+	if _, err := Resolve(t.Context(), srv.Client(), srv.URL, "example.com/missing", "latest"); err == nil {
+		t.Error("Resolve of a missing module succeeded")
+	}
+}
+
+func TestOpenZip(t *testing.T) {
+	data := testZip(t)
+	srv, sent := testProxy(t, data, true)
+	zr, err := OpenZip(t.Context(), srv.Client(), srv.URL, "example.com/tmpl", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := fs.ReadFile(zr, "example.com/tmpl@v1.0.0/template.yaml")
+	if err != nil || string(manifest) != "name: tmpl\n" {
+		t.Fatalf("template.yaml = %q, %v", manifest, err)
+	}
+	if *sent >= int64(len(data))/2 {
+		t.Errorf("reading the manifest fetched %d bytes of a %d bytes zip", *sent, len(data))
+	}
+
+	large, err := fs.ReadFile(zr, "example.com/tmpl@v1.0.0/large.bin")
+	if err != nil || len(large) != 1<<20 {
+		t.Errorf("large.bin: read %d bytes, %v", len(large), err)
+	}
+}
+
+func TestOpenZipNoRange(t *testing.T) {
+	srv, _ := testProxy(t, testZip(t), false)
+	_, err := OpenZip(t.Context(), srv.Client(), srv.URL, "example.com/tmpl", "v1.0.0")
+	if !errors.Is(err, ErrNoRange) {
+		t.Errorf("OpenZip without ranges: %v, want ErrNoRange", err)
+	}
+	if _, err := OpenZip(t.Context(), srv.Client(), srv.URL, "example.com/tmpl", "v2.0.0"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("OpenZip of a missing version: %v, want 404", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package header prepends comment banners, such as copyright notices, to
+// generated source files.
+package header
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// prefixes maps file extensions and names to their line comment prefix.
+var prefixes = map[string]string{
+	".go":        "//",
+	".c":         "//",
+	".h":         "//",
+	".cpp":       "//",
+	".java":      "//",
+	".js":        "//",
+	".ts":        "//",
+	".kt":        "//",
+	".proto":     "//",
+	".rs":        "//",
+	".scala":     "//",
+	".swift":     "//",
+	".py":        "#",
+	".rb":        "#",
+	".sh":        "#",
+	".bash":      "#",
+	".tf":        "#",
+	".toml":      "#",
+	".yaml":      "#",
+	".yml":       "#",
+	".lua":       "--",
+	".sql":       "--",
+	"Makefile":   "#",
+	"Dockerfile": "#",
+}
+
+// Prefix returns the line comment prefix for the file name, preferring the
+// overrides keyed by extension, or "" if the comment syntax is unknown.
+func Prefix(name string, overrides map[string]string) string {
+	base := path.Base(name)
+	ext := path.Ext(base)
+	if prefix, ok := overrides[ext]; ok {
+		return prefix
+	}
+	if prefix, ok := prefixes[base]; ok {
+		return prefix
+	}
+	return prefixes[ext]
+}
+
+// Comment turns text into a block of line comments using prefix.
+func Comment(text, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString(prefix)
+		if line != "" {
+			b.WriteString(" ")
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Insert prepends banner to data followed by a blank line, after any "#!"
+// interpreter line. Data already starting with the banner is left as is.
+func Insert(data []byte, banner string) []byte {
+	var shebang []byte
+	if bytes.HasPrefix(data, []byte("#!")) {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		shebang, data = data[:end], data[end:]
+	}
+	if bytes.HasPrefix(data, []byte(banner)) {
+		return append(shebang, data...)
+	}
+
+	out := make([]byte, 0, len(shebang)+len(banner)+1+len(data))
+	out = append(out, shebang...)
+	out = append(out, banner...)
+	out = append(out, '\n')
+	return append(out, data...)
+}
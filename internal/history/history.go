@@ -0,0 +1,199 @@
+// Package history records the projects gonew generated, with their answers.
+// Answers can include tokens and internal hostnames, so the store is
+// encrypted with AES-GCM under a key kept in the OS keychain. Where no
+// keychain is available the key falls back to a file only the user can read.
+package history
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/betterde/gonew/internal/lockfile"
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	keyringService = "gonew"
+	keyringUser    = "history"
+	storeName      = "history.enc"
+	keyName        = "history.key"
+)
+
+// Entry is a generated project. Template lists the templates applied, with
+// their versions, as in "example.com/base@v1.2.0+example.com/grpc@v0.3.1".
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Template string            `json:"template"`
+	Module   string            `json:"module"`
+	Dir      string            `json:"dir"`
+	Answers  map[string]string `json:"answers"`
+}
+
+// Dir returns the directory holding the history store.
+func Dir() (string, error) {
+	return paths.State()
+}
+
+// Load returns the recorded entries, oldest first.
+func Load() ([]Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, storeName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := loadKey(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := decrypt(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("history: %v", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("history: %v", err)
+	}
+	return entries, nil
+}
+
+// Add records entry. Concurrent invocations take turns, so none of their
+// entries is lost.
+func Add(entry Entry) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return lockfile.Update(filepath.Join(dir, storeName), func() error {
+		return add(dir, entry)
+	})
+}
+
+func add(dir string, entry Entry) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+
+	key, err := loadKey(dir, true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	data, err := encrypt(key, plain)
+	if err != nil {
+		return err
+	}
+	return lockfile.WriteFile(filepath.Join(dir, storeName), data, 0600)
+}
+
+// Purge deletes the store and its key.
+func Purge() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	// Without the directory there is no store to remove, and nothing to
+	// lock.
+	if unlock, err := lockfile.Lock(filepath.Join(dir, storeName)); err == nil {
+		defer unlock()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	for _, name := range []string{storeName, keyName} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// Without a keychain the key only lived in the file removed above, and
+	// a key left behind protects nothing once the store is gone.
+	keyring.Delete(keyringService, keyringUser)
+	return nil
+}
+
+// loadKey returns the store key from the keychain, or from the fallback key
+// file, generating one if create is set.
+func loadKey(dir string, create bool) ([]byte, error) {
+	secret, err := keyring.Get(keyringService, keyringUser)
+	if err == nil {
+		return base64.StdEncoding.DecodeString(secret)
+	}
+	// Any error but a missing key means there is no usable keychain, such
+	// as on a server without a Secret Service.
+	keychain := errors.Is(err, keyring.ErrNotFound)
+
+	keyFile := filepath.Join(dir, keyName)
+	if data, err := os.ReadFile(keyFile); err == nil {
+		return base64.StdEncoding.DecodeString(string(data))
+	}
+	if !create {
+		return nil, errors.New("history: encryption key not found, run gonew history purge to start over")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	secret = base64.StdEncoding.EncodeToString(key)
+	if keychain && keyring.Set(keyringService, keyringUser, secret) == nil {
+		return key, nil
+	}
+	if err := lockfile.WriteFile(keyFile, []byte(secret), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func encrypt(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("store is truncated")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/zalando/go-keyring"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	plain := []byte(`[{"answers":{"Token":"secret"}}]`)
+	data, err := encrypt(key, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Error("encrypted store holds the plain answers")
+	}
+	if got, err := decrypt(key, data); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("decrypt = %q, %v, want %q", got, err, plain)
+	}
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+	for name, tt := range map[string]struct{ key, data []byte }{
+		"wrong key": {bytes.Repeat([]byte{2}, 32), data},
+		"tampered":  {key, tampered},
+		"truncated": {key, data[:4]},
+		"short key": {key[:7], data},
+	} {
+		if _, err := decrypt(tt.key, tt.data); err == nil {
+			t.Errorf("decrypt with %s succeeded", name)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(paths.HomeEnv, t.TempDir())
+
+	if entries, err := Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("Load of no store = %v, %v", entries, err)
+	}
+	first := Entry{Time: time.Unix(1, 0).UTC(), Template: "example.com/tpl@v1.0.0", Module: "example.com/app", Dir: "app", Answers: map[string]string{"Token": "secret"}}
+	second := Entry{Time: time.Unix(2, 0).UTC(), Template: "example.com/tpl@v1.1.0", Module: "example.com/other", Dir: "other"}
+	for _, entry := range []Entry{first, second} {
+		if err := Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Answers["Token"] != "secret" || entries[1].Module != "example.com/other" {
+		t.Errorf("Load = %+v, want both entries in order", entries)
+	}
+
+	// A store encrypted under another key is not readable.
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyName)); !os.IsNotExist(err) {
+		t.Errorf("key file written although the keychain is available: %v", err)
+	}
+	if err := keyring.Set(keyringService, keyringUser, "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := Load(); err == nil {
+		t.Errorf("Load with the wrong key = %+v, want an error", entries)
+	}
+
+	if err := Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := Load(); err != nil || len(entries) != 0 {
+		t.Errorf("Load after Purge = %v, %v, want no entries", entries, err)
+	}
+	if _, err := keyring.Get(keyringService, keyringUser); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("key left in the keychain after Purge: %v", err)
+	}
+}
+
+func TestHistoryKeyFile(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keychain"))
+	t.Cleanup(keyring.MockInit)
+	t.Setenv(paths.HomeEnv, t.TempDir())
+
+	if err := Add(Entry{Module: "example.com/app"}); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filepath.Join(dir, keyName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", perm)
+	}
+	if entries, err := Load(); err != nil || len(entries) != 1 {
+		t.Errorf("Load = %v, %v, want the entry back", entries, err)
+	}
+
+	// Without the key the store cannot be read.
+	if err := os.Remove(filepath.Join(dir, keyName)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("Load without the key succeeded")
+	}
+}
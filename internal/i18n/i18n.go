@@ -0,0 +1,103 @@
+// Package i18n looks up translated messages in the catalogs a template
+// supplies, for templates generating localized user-facing strings.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalogs holds the messages of a template per locale. Each catalog is a
+// YAML or JSON file named after its BCP-47 tag, such as de.yaml or
+// pt-BR.json, mapping message keys to messages.
+type Catalogs struct {
+	tags     []language.Tag
+	messages []map[string]string
+	fallback int
+	current  int
+}
+
+// Load reads the catalogs in dir. Fallback names the catalog used for keys
+// missing from the selected one; empty means the first catalog.
+func Load(dir, fallback string) (*Catalogs, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalogs{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		tag, err := language.Parse(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s: %v", entry.Name(), err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		messages := make(map[string]string)
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("catalog %s: %v", entry.Name(), err)
+		}
+		c.tags = append(c.tags, tag)
+		c.messages = append(c.messages, messages)
+	}
+	if len(c.tags) == 0 {
+		return nil, fmt.Errorf("no message catalogs in %s", dir)
+	}
+
+	if fallback != "" {
+		tag, err := language.Parse(fallback)
+		if err != nil {
+			return nil, fmt.Errorf("fallback locale: %v", err)
+		}
+		c.fallback = -1
+		for i, t := range c.tags {
+			if t == tag {
+				c.fallback = i
+			}
+		}
+		if c.fallback < 0 {
+			return nil, fmt.Errorf("no message catalog for the fallback locale %s", fallback)
+		}
+	}
+	c.current = c.fallback
+	return c, nil
+}
+
+// Use selects the catalog best matching locale, such as de for de-AT.
+func (c *Catalogs) Use(locale string) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return err
+	}
+	// The matcher prefers its first tag when nothing matches.
+	tags := append([]language.Tag{c.tags[c.fallback]}, c.tags...)
+	_, i, confidence := language.NewMatcher(tags).Match(tag)
+	if confidence == language.No || i == 0 {
+		c.current = c.fallback
+		return nil
+	}
+	c.current = i - 1
+	return nil
+}
+
+// T returns the message for key in the selected catalog, or else in the
+// fallback catalog.
+func (c *Catalogs) T(key string) (string, error) {
+	if message, ok := c.messages[c.current][key]; ok {
+		return message, nil
+	}
+	if message, ok := c.messages[c.fallback][key]; ok {
+		return message, nil
+	}
+	return "", fmt.Errorf("t: no message %q in the %s catalog", key, c.tags[c.current])
+}
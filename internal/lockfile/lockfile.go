@@ -0,0 +1,77 @@
+// Package lockfile guards the files gonew shares between invocations, such
+// as the cache, the history and the template index, against concurrent
+// writers: updates hold an advisory lock and replace files atomically, so
+// readers never see a file half written.
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock takes an exclusive advisory lock on filename, waiting for other
+// holders, and returns the function releasing it. The lock is kept on a
+// separate filename+".lock" file so that filename itself can be replaced
+// while locked. The directory of filename must exist.
+func Lock(filename string) (func() error, error) {
+	f, err := os.OpenFile(filename+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		err := unlock(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// Update runs fn holding the lock on filename.
+func Update(filename string, fn func() error) error {
+	release, err := Lock(filename)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
+
+// WriteFile writes data to filename like os.WriteFile, but through a
+// temporary file in the same directory renamed over filename once synced,
+// so the file holds either its old or its new contents.
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package normalize fixes up the encoding and line endings of rendered text files.
+package normalize
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+var bom = []byte("\xef\xbb\xbf")
+
+// IsBinary reports whether data looks like binary content rather than text.
+func IsBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// binaryExtensions are the extensions of binary formats, such as images
+// and archives, whose files may not hold a NUL byte early enough for
+// IsBinary to notice.
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".ico": true,
+	".webp": true, ".bmp": true, ".pdf": true, ".woff": true, ".woff2": true,
+	".ttf": true, ".otf": true, ".eot": true, ".zip": true, ".gz": true,
+	".tgz": true, ".bz2": true, ".xz": true, ".zst": true, ".jar": true,
+	".wasm": true, ".so": true, ".dylib": true, ".dll": true, ".exe": true,
+	".a": true, ".o": true, ".class": true, ".pyc": true, ".db": true,
+	".sqlite": true,
+}
+
+// IsBinaryFile reports whether the file name with content data is binary,
+// by its extension or its content.
+func IsBinaryFile(name string, data []byte) bool {
+	return binaryExtensions[strings.ToLower(path.Ext(name))] || IsBinary(data)
+}
+
+// StripBOM removes a leading UTF-8 byte order mark.
+func StripBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, bom)
+}
+
+// MixedLineEndings reports whether data uses both LF and CRLF line endings.
+func MixedLineEndings(data []byte) bool {
+	crlf := bytes.Count(data, []byte("\r\n"))
+	return crlf > 0 && crlf != bytes.Count(data, []byte("\n"))
+}
+
+// CheckLineEndings validates a line ending mode: "lf", "crlf", "native",
+// or empty to keep line endings as they are.
+func CheckLineEndings(mode string) error {
+	switch mode {
+	case "", "lf", "crlf", "native":
+		return nil
+	}
+	return fmt.Errorf("unknown line ending mode %q, must be lf, crlf or native", mode)
+}
+
+// LineEndings converts every line ending in data to mode.
+func LineEndings(data []byte, mode string) []byte {
+	if mode == "native" {
+		mode = "lf"
+		if runtime.GOOS == "windows" {
+			mode = "crlf"
+		}
+	}
+
+	switch mode {
+	case "lf":
+		return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	case "crlf":
+		lf := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	}
+	return data
+}
+
+// SquashBlankLines collapses runs of blank lines, such as those left by
+// conditional blocks, into a single blank line. Lines holding only spaces and
+// tabs count as blank.
+func SquashBlankLines(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	out := make([]byte, 0, len(data))
+	blank := false
+	for _, line := range lines {
+		if len(bytes.Trim(line, " \t\r\n")) == 0 && bytes.HasSuffix(line, []byte("\n")) {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line...)
+	}
+	return out
+}
+
+// FinalNewline ends non-empty data with exactly one line ending, removing
+// trailing blank lines.
+func FinalNewline(data []byte) []byte {
+	trimmed := bytes.TrimRight(data, " \t\r\n")
+	if len(trimmed) == 0 {
+		return trimmed
+	}
+	eol := []byte("\n")
+	if bytes.Contains(data, []byte("\r\n")) {
+		eol = []byte("\r\n")
+	}
+	return append(trimmed, eol...)
+}
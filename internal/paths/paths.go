@@ -0,0 +1,143 @@
+// Package paths locates the files gonew keeps between runs, following the
+// XDG base directory specification: configuration under $XDG_CONFIG_HOME,
+// the template cache under $XDG_CACHE_HOME, the history under
+// $XDG_STATE_HOME and other data under $XDG_DATA_HOME, each in a gonew
+// subdirectory. GONEW_HOME moves all of them under a single directory.
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// HomeEnv names the environment variable overriding every directory.
+const HomeEnv = "GONEW_HOME"
+
+// Config returns the directory of the gonew configuration.
+func Config() (string, error) {
+	return dir("config", "XDG_CONFIG_HOME", ".config", os.UserConfigDir)
+}
+
+// Cache returns the directory of the template cache.
+func Cache() (string, error) {
+	return dir("cache", "XDG_CACHE_HOME", ".cache", os.UserCacheDir)
+}
+
+// State returns the directory of state kept between runs, such as the
+// history.
+func State() (string, error) {
+	return dir("state", "XDG_STATE_HOME", filepath.Join(".local", "state"), localAppData("state"))
+}
+
+// Data returns the directory of data files, such as template indexes.
+func Data() (string, error) {
+	return dir("data", "XDG_DATA_HOME", filepath.Join(".local", "share"), localAppData("data"))
+}
+
+// dir returns the gonew directory of a kind: sub of GONEW_HOME if set, or
+// else gonew under the XDG variable env, defaulting to def in the home
+// directory. Windows has no XDG defaults, so there it is gonew under the
+// directory native returns.
+func dir(sub, env, def string, native func() (string, error)) (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return filepath.Join(home, sub), nil
+	}
+	// The specification requires absolute paths and ignores others.
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "gonew"), nil
+	}
+	if runtime.GOOS == "windows" {
+		base, err := native()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, "gonew"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, def, "gonew"), nil
+}
+
+// localAppData returns a function returning sub of %LocalAppData%, keeping
+// the state and data of gonew apart from its cache on Windows.
+func localAppData(sub string) func() (string, error) {
+	return func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "gonew", sub), nil
+	}
+}
+
+// A move relocates a file or directory from where an earlier gonew kept it.
+type move struct {
+	legacy func() (string, error)
+	dir    func() (string, error)
+	name   string
+}
+
+// moves lists the files earlier versions kept in the platform's config and
+// cache directories.
+var moves = []move{
+	{legacy: legacy(os.UserCacheDir), dir: Cache, name: "manifests"},
+	{legacy: legacy(os.UserCacheDir), dir: Cache, name: "bundles"},
+	{legacy: legacy(os.UserConfigDir), dir: State, name: "history.enc"},
+	{legacy: legacy(os.UserConfigDir), dir: State, name: "history.key"},
+}
+
+func legacy(base func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		dir, err := base()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "gonew"), nil
+	}
+}
+
+// Migrate moves the files of earlier versions to their current location,
+// leaving alone any already there. It returns the paths moved, and the
+// errors of those that could not be, which stay where they are. Nothing is
+// moved into GONEW_HOME, which often isolates a single run.
+func Migrate() (moved []string, err error) {
+	if os.Getenv(HomeEnv) != "" {
+		return nil, nil
+	}
+	for _, m := range moves {
+		from, ferr := m.legacy()
+		to, terr := m.dir()
+		if ferr != nil || terr != nil {
+			// Without a home directory there is nothing to migrate.
+			continue
+		}
+		from, to = filepath.Join(from, m.name), filepath.Join(to, m.name)
+		if from == to {
+			continue
+		}
+		if _, serr := os.Lstat(from); serr != nil {
+			continue
+		}
+		if _, serr := os.Lstat(to); serr == nil {
+			continue
+		}
+		if merr := os.MkdirAll(filepath.Dir(to), 0700); merr != nil {
+			err = errors.Join(err, merr)
+			continue
+		}
+		// Another invocation migrating at the same time leaves nothing to
+		// rename.
+		if rerr := os.Rename(from, to); rerr != nil {
+			if !os.IsNotExist(rerr) {
+				err = errors.Join(err, rerr)
+			}
+			continue
+		}
+		moved = append(moved, from)
+	}
+	return moved, err
+}
@@ -0,0 +1,45 @@
+// Package ports picks free TCP ports on the local machine, so that the
+// services of a generated project do not collide with running ones.
+package ports
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Free returns preferred if it is free, else the first free port after it,
+// skipping the ports in taken, such as those already given to other
+// variables. Without a preferred port, or when none after it is free, the
+// system picks one.
+func Free(preferred int, taken map[int]bool) (int, error) {
+	if preferred > 0 {
+		for port := preferred; port <= 65535; port++ {
+			if !taken[port] && available(port) {
+				return port, nil
+			}
+		}
+	}
+	for range 100 {
+		l, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return 0, fmt.Errorf("finding a free port: %v", err)
+		}
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		if !taken[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port found")
+}
+
+// available reports whether port can be listened on, on every interface.
+func available(port int) bool {
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
@@ -1,13 +1,1173 @@
 package project
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/version"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/betterde/gonew/internal/catalog"
+	"github.com/betterde/gonew/internal/cloud"
+	"github.com/betterde/gonew/internal/expr"
+	"github.com/betterde/gonew/internal/glob"
+	"github.com/betterde/gonew/internal/normalize"
+	"github.com/betterde/gonew/internal/readme"
+	"github.com/betterde/gonew/internal/release"
+	"github.com/betterde/gonew/internal/render"
+	"github.com/betterde/gonew/internal/safepath"
+	"github.com/betterde/gonew/pkg/validate"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the manifest file at the root of a template.
+const FileName = "template.yaml"
+
+// SchemaVersion is the latest manifest schema this gonew understands.
+// Manifests declare the schema they need with schema, the first by default.
+const SchemaVersion = 1
+
+// ReadmeFileName is the name of the template's instructions for users,
+// rendered with their answers once the project is generated.
+const ReadmeFileName = "TEMPLATE_README.md"
+
+// What to do with the rendered TEMPLATE_README.md.
+const (
+	ReadmeDisplay = "display"
+	ReadmeWrite   = "write"
+)
+
+// Lifecycle states of a template. Experimental templates are only generated
+// when allowed explicitly, deprecated ones with a warning, and blocked ones
+// not at all.
+const (
+	StateExperimental = "experimental"
+	StateStable       = "stable"
+	StateDeprecated   = "deprecated"
+	StateBlocked      = "blocked"
+)
+
+// ValidState reports whether state is a lifecycle state; empty means stable.
+func ValidState(state string) bool {
+	switch state {
+	case "", StateExperimental, StateStable, StateDeprecated, StateBlocked:
+		return true
+	}
+	return false
+}
+
+// HelpersDir is the slash-separated directory, relative to the template
+// root, of the template's helper program, a Go main package whose output
+// adds to the context of the template's files. Like the rest of .gonew, it
+// is not generated.
+const HelpersDir = ".gonew/helpers"
+
+// ReadmeDestination is where a written TEMPLATE_README.md is generated.
+const ReadmeDestination = "docs/SCAFFOLD.md"
+
 type Variable struct {
 	Name        string `yaml:"name"`
 	Placeholder string `yaml:"placeholder"`
+	// Help explains the answers expected, such as the format of a DSN, and
+	// Example is one of them. Prompts show them when the answer is "?".
+	Help    string `yaml:"help"`
+	Example string `yaml:"example"`
+	Default string `yaml:"default"`
+	// From names a cloud provider, such as cloud.aws.region, whose value
+	// replaces Default when it can be determined.
+	From string `yaml:"from"`
+	// Generated is an expression, such as "randAlphaNum 32", computing the
+	// value instead of prompting for it.
+	Generated string `yaml:"generated"`
+	// Type is the type answers must parse as: string, the default, int,
+	// float, bool, list, port, a TCP port number, locale, a BCP-47
+	// language tag, or select, one of Options. Booleans are asked as yes or
+	// no questions and selects as a list to choose from.
+	Type string `yaml:"type"`
+	// Allocate makes the default of a port variable a port free on the
+	// local machine, the Default if it is free or else the next one, and
+	// different from the answers to the other port variables.
+	Allocate bool `yaml:"allocate"`
+	// Options are the answers a select variable offers.
+	Options []string `yaml:"options"`
+	// Min and Max bound the answers of int and float variables.
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// ValidateRemote checks answers against an HTTP endpoint, such as a
+	// service catalog knowing the existing teams.
+	ValidateRemote *RemoteValidation `yaml:"validate_remote"`
+	// Validate is a regular expression answers must match, such as
+	// "^[a-z][a-z0-9-]*$", or the name of a validator: semver, hostname,
+	// go_package, identifier or module_path. ValidateMessage, if set,
+	// replaces the error shown for answers it rejects.
+	Validate        string `yaml:"validate"`
+	ValidateMessage string `yaml:"validate_message"`
+	// When is a condition over the earlier answers and the selected
+	// features, such as "UseDatabase == true", under which the variable is
+	// asked. Otherwise its answer is empty.
+	When string `yaml:"when"`
+	// Required, true unless set, rejects empty answers. Optional
+	// variables accept an empty answer even when they have a default.
+	Required *bool `yaml:"required"`
+	// Secret answers, such as tokens, are left out of the answers file
+	// written into the project, like generated values are.
+	Secret bool `yaml:"secret"`
+}
+
+// Value converts answer to the type of the variable: an int, float64 or
+// bool, or for lists a []any decoded from a JSON array, as answer files give
+// them, or else a []string split at commas. Other answers, and answers that
+// do not parse, stay strings.
+func (v Variable) Value(answer string) any {
+	switch v.Type {
+	case "int", "port":
+		if i, err := strconv.Atoi(answer); err == nil {
+			return i
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(answer, 64); err == nil {
+			return f
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(answer); err == nil {
+			return b
+		}
+	case "list":
+		var list []any
+		if err := json.Unmarshal([]byte(answer), &list); err == nil {
+			return list
+		}
+		if answer == "" {
+			return []string{}
+		}
+		items := strings.Split(answer, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items
+	}
+	return answer
+}
+
+// Rule returns the rule answers to v must satisfy: its type, options and
+// bounds, its validate rule, and a value unless it is optional.
+func (v Variable) Rule() (validate.Rule, error) {
+	spec := validate.Spec{
+		Required: v.IsRequired(),
+		Type:     v.Type,
+		Options:  v.Options,
+		Min:      v.Min,
+		Max:      v.Max,
+	}
+	if v.Validate != "" {
+		if _, err := validate.Named(v.Validate); err == nil {
+			spec.Named = []string{v.Validate}
+		} else {
+			spec.Pattern = v.Validate
+		}
+	}
+	rule, err := validate.Compile(spec)
+	if err != nil || v.ValidateMessage == "" {
+		return rule, err
+	}
+	return validate.RuleFunc(func(value string) error {
+		err := rule.Validate(value)
+		if err != nil && value != "" {
+			return errors.New(v.ValidateMessage)
+		}
+		return err
+	}), nil
+}
+
+// Asked reports whether v is asked given the answers so far, looked up in
+// vars, as its when condition says.
+func (v Variable) Asked(vars expr.Vars) (bool, error) {
+	if v.When == "" {
+		return true, nil
+	}
+	e, err := expr.Parse(v.When)
+	if err != nil {
+		return false, err
+	}
+	ok, err := e.Bool(vars)
+	if err != nil {
+		return false, fmt.Errorf("variable %s: when %v", v.Name, err)
+	}
+	return ok, nil
+}
+
+// IsRequired reports whether v needs a non-empty answer.
+func (v Variable) IsRequired() bool {
+	return v.Required == nil || *v.Required
+}
+
+// checkOptions reports options and bounds that do not fit the type of v.
+// Defaults holding the left delimiter are rendered, so are not checked
+// against the options.
+func (v Variable) checkOptions(left string) error {
+	if v.Type == "select" {
+		if len(v.Options) == 0 {
+			return fmt.Errorf("select needs options")
+		}
+		if v.Default != "" && !strings.Contains(v.Default, left) && !slices.Contains(v.Options, v.Default) {
+			return fmt.Errorf("default %q is not one of the options", v.Default)
+		}
+	} else if len(v.Options) > 0 {
+		return fmt.Errorf("options need type select")
+	}
+	if v.Allocate && v.Type != "port" {
+		return fmt.Errorf("allocate needs type port")
+	}
+	if v.Min != nil || v.Max != nil {
+		if v.Type != "int" && v.Type != "float" {
+			return fmt.Errorf("min and max need type int or float")
+		}
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return fmt.Errorf("min %v is greater than max %v", *v.Min, *v.Max)
+		}
+	}
+	return nil
+}
+
+// RemoteValidation checks an answer with a request to URL, where {value} is
+// replaced by the escaped answer. A 200 or 204 response accepts the answer,
+// 400, 404, 410 and 422 reject it. Header values may refer to environment
+// variables as $NAME, for tokens, among those the user allows in the
+// configuration file. Timeout is a positive duration, 5s by default.
+type RemoteValidation struct {
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout string            `yaml:"timeout"`
+}
+
+// Check reports problems in the declaration of r.
+func (r *RemoteValidation) Check() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("url %q must be an http or https URL", r.URL)
+	}
+	if !strings.Contains(r.URL, "{value}") {
+		return fmt.Errorf("url %q has no {value}", r.URL)
+	}
+	switch strings.ToUpper(r.Method) {
+	case "", http.MethodGet, http.MethodHead:
+	default:
+		return fmt.Errorf("unsupported method %s, must be GET or HEAD", r.Method)
+	}
+	if r.Timeout != "" {
+		d, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout: %v", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout %s must be positive", r.Timeout)
+		}
+	}
+	return nil
+}
+
+// Feature is an optional part of a template the user can select. The files
+// matching its globs are only generated when it is selected, and its selection
+// is available to templates as .Features.<name>.
+type Feature struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Default     bool     `yaml:"default"`
+	Files       []string `yaml:"files"`
+}
+
+// Layout is a directory structure a template can be generated in. Paths maps
+// slash-separated directory prefixes of the template, such as "cmd/app/", to
+// the prefix they are generated under; an empty prefix moves them to the root.
+type Layout struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Paths       map[string]string `yaml:"paths"`
+}
+
+// Map returns the path rel is generated at, replacing its longest matching prefix.
+func (l *Layout) Map(rel string) string {
+	if l == nil {
+		return rel
+	}
+	var from string
+	for prefix := range l.Paths {
+		if strings.HasPrefix(rel, prefix) && len(prefix) > len(from) {
+			from = prefix
+		}
+	}
+	if from == "" {
+		return rel
+	}
+	return l.Paths[from] + strings.TrimPrefix(rel, from)
+}
+
+// Normalize controls how rendered text files are cleaned up. LineEndings is
+// "lf", "crlf" or "native"; empty keeps the line endings of the template.
+// EditorConfig applies the rules of the template's .editorconfig.
+// SquashBlankLines collapses runs of blank lines into one, and FinalNewline
+// ends files with a single line ending.
+type Normalize struct {
+	LineEndings      string `yaml:"line_endings"`
+	StripBOM         bool   `yaml:"strip_bom"`
+	EditorConfig     bool   `yaml:"editorconfig"`
+	SquashBlankLines bool   `yaml:"squash_blank_lines"`
+	FinalNewline     bool   `yaml:"final_newline"`
+}
+
+// Whitespace controls the whitespace around block tags, such as {{ if }} or
+// {% for %}. TrimBlocks removes the newline after a block tag, and
+// LStripBlocks the indentation before a block tag starting a line, so
+// conditional blocks do not leave ragged blank lines.
+type Whitespace struct {
+	TrimBlocks   bool `yaml:"trim_blocks"`
+	LStripBlocks bool `yaml:"lstrip_blocks"`
+}
+
+// Delims returns the action delimiters of the template, {{ and }} unless
+// it declares others.
+func (c *Config) Delims() (left, right string) {
+	if len(c.Delimiters) == 2 {
+		return c.Delimiters[0], c.Delimiters[1]
+	}
+	return "{{", "}}"
+}
+
+// Formatter is a command run on the generated files matching Glob once the
+// project is written, with the file paths appended to its arguments.
+type Formatter struct {
+	Glob    string `yaml:"glob"`
+	Command string `yaml:"command"`
+}
+
+// PostProcessor is a command each rendered file matching Glob is piped
+// through, on stdin and stdout, before it is written.
+type PostProcessor struct {
+	Glob    string `yaml:"glob"`
+	Command string `yaml:"command"`
+}
+
+// Header is a banner, such as a license notice, prepended to generated
+// source files as comments. Text is rendered like a template file, Include and
+// Exclude select the files by glob, and Comments overrides the line comment
+// prefix per file extension.
+type Header struct {
+	Text     string            `yaml:"text"`
+	Include  []string          `yaml:"include"`
+	Exclude  []string          `yaml:"exclude"`
+	Comments map[string]string `yaml:"comments"`
+}
+
+// I18n enables the t template function, translating message keys with the
+// catalogs in the Catalogs directory, relative to the template root, into the
+// locale answered for Variable. Variable defaults to the first variable of
+// type locale, and Fallback, the locale of keys missing from a catalog, to
+// the first catalog.
+type I18n struct {
+	Catalogs string `yaml:"catalogs"`
+	Variable string `yaml:"variable"`
+	Fallback string `yaml:"fallback"`
+}
+
+// LocaleVariable returns the name of the variable selecting the catalog.
+func (c *Config) LocaleVariable() string {
+	if c.I18n.Variable != "" {
+		return c.I18n.Variable
+	}
+	for _, variable := range c.Variables {
+		if variable.Type == "locale" {
+			return variable.Name
+		}
+	}
+	return ""
+}
+
+// Catalog registers the generated service in a service catalog with the named
+// provider, backstage or opslevel. The entity, URL and Target are rendered
+// like template files, and TokenEnv names the environment variable holding
+// the catalog's API token.
+type Catalog struct {
+	Provider string        `yaml:"provider"`
+	URL      string        `yaml:"url"`
+	Target   string        `yaml:"target"`
+	TokenEnv string        `yaml:"token_env"`
+	Entity   CatalogEntity `yaml:"entity"`
+}
+
+// CatalogEntity describes the generated service. Name defaults to the last
+// element of the module path, Type to service and Lifecycle to experimental.
+type CatalogEntity struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Owner       string `yaml:"owner"`
+	Type        string `yaml:"type"`
+	Lifecycle   string `yaml:"lifecycle"`
+	System      string `yaml:"system"`
+}
+
+// PullRequest is the title and description of the pull request opened by
+// gonew init --create-pr, rendered like template files. The provenance of
+// the project is appended to the description.
+type PullRequest struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+}
+
+// Constraint is a condition over the answers, such as
+// "PortHTTP != PortGRPC", checked before anything is rendered. Message
+// explains a failed constraint to the user.
+type Constraint struct {
+	Expr    string `yaml:"expr"`
+	Message string `yaml:"message"`
+}
+
+// FileCondition generates the files and directories matching Glob only
+// when When holds, such as "UseDatabase" for internal/db/**. When is a
+// condition over the answers and the selected features, like the when of
+// variables.
+type FileCondition struct {
+	Glob string `yaml:"glob"`
+	When string `yaml:"when"`
+}
+
+// Generate controls how template files are turned into generated files.
+type Generate struct {
+	Matrix []Matrix `yaml:"matrix"`
+}
+
+// Matrix stamps out the files matching its globs once per element of the
+// list variable Variable, such as one handler per entity. Each copy is
+// rendered with the element as .Item and its position, from 0, as .Index,
+// which its templated file or directory name must use to tell the copies
+// apart.
+type Matrix struct {
+	Files    []string `yaml:"files"`
+	Variable string   `yaml:"variable"`
+}
+
+// Matrix returns the matrix entry generating the file at rel, a
+// slash-separated path relative to the template root, or nil.
+func (c *Config) Matrix(rel string) *Matrix {
+	for i, m := range c.Generate.Matrix {
+		if glob.MatchAny(m.Files, rel) {
+			return &c.Generate.Matrix[i]
+		}
+	}
+	return nil
+}
+
+// Pipelines, the order in which template files are rewritten for the
+// destination module and rendered. Rewriting first, the default, parses the
+// template's own sources; rendering first suits templates that inject
+// package clauses or imports through variables, whose sources only parse
+// once rendered.
+const (
+	PipelineRewriteFirst = "rewrite_first"
+	PipelineRenderFirst  = "render_first"
+)
+
+// GoRewrite controls how the module path is rewritten in Go files, whose
+// sources are parsed for it. Files matching the AfterRender globs, such as
+// sources with template placeholders inside identifiers, are rewritten once
+// rendered rather than before, and files matching the Skip globs, such as
+// snippets that never parse, are not rewritten at all. Globs are matched
+// against slash-separated paths relative to the template root.
+type GoRewrite struct {
+	AfterRender []string `yaml:"after_render"`
+	Skip        []string `yaml:"skip"`
+}
+
+// ComposeReadme describes the README.md composed for the generated project
+// when the template does not generate one: a title, the name of the module
+// by default, a description, badges, the commands to get started and
+// further sections. Text is rendered like template files.
+type ComposeReadme struct {
+	Title          string          `yaml:"title"`
+	Description    string          `yaml:"description"`
+	Badges         []ReadmeBadge   `yaml:"badges"`
+	GettingStarted []string        `yaml:"getting_started"`
+	Sections       []ReadmeSection `yaml:"sections"`
+}
+
+// ReadmeBadge is a built-in badge selected by Name, such as go-reference,
+// or an Image linking to Link described by Alt.
+type ReadmeBadge struct {
+	Name  string `yaml:"name"`
+	Alt   string `yaml:"alt"`
+	Image string `yaml:"image"`
+	Link  string `yaml:"link"`
+}
+
+// ReadmeSection is a section of a composed README, its Body Markdown.
+type ReadmeSection struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+}
+
+// check reports problems in the declaration of r.
+func (r *ComposeReadme) check() error {
+	for i, badge := range r.Badges {
+		switch {
+		case badge.Name != "":
+			if !slices.Contains(readme.Builtins(), badge.Name) {
+				return fmt.Errorf("unknown badge %s, must be one of %s", badge.Name, strings.Join(readme.Builtins(), ", "))
+			}
+		case badge.Image == "":
+			return fmt.Errorf("badge %d needs a name or an image", i+1)
+		}
+	}
+	for i, section := range r.Sections {
+		if section.Title == "" {
+			return fmt.Errorf("section %d has no title", i+1)
+		}
+	}
+	return nil
+}
+
+// Release bootstraps the versioning and releases of the generated project:
+// a CHANGELOG.md, the internal/version package and the configuration of
+// Tool, goreleaser or release-please. They are generated only when Feature,
+// if set, is selected. Tool and Main, the package released as a binary,
+// are rendered like template files, so that features can choose the tool.
+type Release struct {
+	Feature string `yaml:"feature"`
+	Tool    string `yaml:"tool"`
+	Main    string `yaml:"main"`
+}
+
+// check reports problems in the declaration of r for the template c.
+func (r *Release) check(c *Config) error {
+	if r.Tool == "" {
+		return fmt.Errorf("no tool, must be one of %s", strings.Join(release.Tools, ", "))
+	}
+	if left, _ := c.Delims(); !strings.Contains(r.Tool, left) && !slices.Contains(release.Tools, r.Tool) {
+		return fmt.Errorf("unknown tool %s, must be one of %s", r.Tool, strings.Join(release.Tools, ", "))
+	}
+	if r.Feature != "" && !slices.ContainsFunc(c.Features, func(f Feature) bool { return f.Name == r.Feature }) {
+		return fmt.Errorf("unknown feature %s", r.Feature)
+	}
+	return nil
+}
+
+// Hooks are commands run for the template around the generation.
+type Hooks struct {
+	// PostInit runs in the generated project once it is written and
+	// formatted, such as git init or make setup.
+	PostInit []Hook `yaml:"post_init"`
+	// PreInit runs in the current directory once the questions are
+	// answered and before any file is written, to check prerequisites such
+	// as docker being installed.
+	PreInit []Hook `yaml:"pre_init"`
+}
+
+// DefaultHookTimeout is how long a hook may run unless it sets its own
+// timeout.
+const DefaultHookTimeout = 5 * time.Minute
+
+// Hook is a command run for the template, rendered like template files and
+// split into arguments at spaces, without a shell. Timeout is a duration,
+// DefaultHookTimeout if empty. Env lists environment variables that must be
+// set, checked before the command, which may then be empty. Message, also
+// rendered, replaces the error reported when the hook fails.
+type Hook struct {
+	Command string   `yaml:"command"`
+	Timeout string   `yaml:"timeout"`
+	Env     []string `yaml:"env"`
+	Message string   `yaml:"message"`
+}
+
+// String returns the command of the hook, or the variables it checks.
+func (h Hook) String() string {
+	if h.Command != "" {
+		return h.Command
+	}
+	return "env " + strings.Join(h.Env, " ")
+}
+
+// Duration returns how long the hook may run.
+func (h Hook) Duration() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHookTimeout
+}
+
+// check reports problems in the declaration of h.
+func (h Hook) check() error {
+	if strings.TrimSpace(h.Command) == "" && len(h.Env) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	for _, name := range h.Env {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			return fmt.Errorf("%s: invalid environment variable %q", h, name)
+		}
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil {
+			return fmt.Errorf("%s: timeout: %v", h, err)
+		} else if d <= 0 {
+			return fmt.Errorf("%s: timeout must be positive", h)
+		}
+	}
+	return nil
+}
+
+// Artifact is a generated output other than a file, such as a URL or the
+// next command to run. Value is rendered like a template file.
+type Artifact struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
 }
 
 type Config struct {
-	Name               string     `yaml:"name"`
-	Desc               string     `yaml:"desc"`
-	Variables          []Variable `yaml:"variables"`
-	DeleteTemplateFile bool       `yaml:"delete_template_file"`
+	Schema             int             `yaml:"schema"`
+	Name               string          `yaml:"name"`
+	Desc               string          `yaml:"desc"`
+	Engine             string          `yaml:"engine"`
+	Namespace          string          `yaml:"namespace"`
+	Cookiecutter       bool            `yaml:"cookiecutter"`
+	Variables          []Variable      `yaml:"variables"`
+	Ignore             []string        `yaml:"ignore"`
+	Features           []Feature       `yaml:"features"`
+	Layouts            []Layout        `yaml:"layouts"`
+	Normalize          Normalize       `yaml:"normalize"`
+	Formatters         []Formatter     `yaml:"formatters"`
+	PostProcess        []PostProcessor `yaml:"post_process"`
+	Header             Header          `yaml:"header"`
+	DeleteTemplateFile bool            `yaml:"delete_template_file"`
+	VariableMigrations []Migration     `yaml:"variable_migrations"`
+	// Messages are rendered like template files and printed once the
+	// project is written, along with the Artifacts.
+	Messages  []string   `yaml:"messages"`
+	Artifacts []Artifact `yaml:"artifacts"`
+	// Root is the slash-separated directory, relative to the module root,
+	// holding the files to generate, such as "skeleton". Its go.mod, if any,
+	// gives the module path rewritten in the generated sources.
+	Root string `yaml:"root"`
+	// Restore renames files and directories the module zip cannot carry,
+	// such as "gitignore" to ".gitignore" or "_github" to ".github". Keys and
+	// values are slash-separated paths relative to the template root.
+	Restore map[string]string `yaml:"restore"`
+	// Executables are globs of generated files made executable, matched
+	// against the generated paths.
+	Executables []string `yaml:"executables"`
+	// GoVersions are the Go releases, such as "1.22", that template test
+	// builds the generated project with.
+	GoVersions []string `yaml:"go_versions"`
+	// Readme is ReadmeDisplay, the default, to print the rendered
+	// TEMPLATE_README.md once the project is generated, or ReadmeWrite to
+	// generate it as ReadmeDestination.
+	Readme string `yaml:"readme"`
+	// Imports are modules, such as "github.com/org/gonew-vars/common@v1",
+	// whose template.yaml variables are shared by the template. Variables
+	// the template declares itself take precedence.
+	Imports []string `yaml:"imports"`
+	I18n    I18n     `yaml:"i18n"`
+	Catalog *Catalog `yaml:"catalog"`
+	// State is the lifecycle state of the template, stable by default, and
+	// Replacement the template to use instead of a deprecated or blocked one.
+	State       string       `yaml:"state"`
+	Replacement string       `yaml:"replacement"`
+	Constraints []Constraint `yaml:"constraints"`
+	Whitespace  Whitespace   `yaml:"whitespace"`
+	PullRequest PullRequest  `yaml:"pull_request"`
+	Generate    Generate     `yaml:"generate"`
+	// Models are the default entities of the project, which users may
+	// replace with their own models file.
+	Models []Model `yaml:"models"`
+	// Destination restricts the module paths the template generates
+	// projects at.
+	Destination Destination `yaml:"destination"`
+	GoRewrite   GoRewrite   `yaml:"go_rewrite"`
+	// Pipeline is PipelineRewriteFirst, the default, or
+	// PipelineRenderFirst.
+	Pipeline string `yaml:"pipeline"`
+	// Files generates parts of the template only under conditions.
+	Files []FileCondition `yaml:"files"`
+	// RawPaths keeps the answers used in templated file and directory
+	// names as they are, so that an answer such as a package path can
+	// create nested directories. By default they are sanitized to stay
+	// within a single path element.
+	RawPaths bool `yaml:"raw_paths"`
+	// RenderExclude are globs of text files copied without rendering, such
+	// as testdata holding template delimiters. Their module paths are
+	// still rewritten. Binary files are never rendered.
+	RenderExclude []string `yaml:"render_exclude"`
+	// Delimiters replace {{ and }} as the action delimiters of the
+	// template, such as ["[[", "]]"], so that it can generate files that
+	// are Go templates themselves, like Helm charts.
+	Delimiters    []string       `yaml:"delimiters"`
+	Hooks         Hooks          `yaml:"hooks"`
+	ComposeReadme *ComposeReadme `yaml:"compose_readme"`
+	Release       *Release       `yaml:"release"`
+}
+
+// Context returns the data passed to the engine when rendering files.
+// The answers are available at the top level and, when the template
+// declares a namespace, under that key as well. Answers to typed variables
+// are converted to their type, see Variable.Value. The selected features
+// are available as .Features and the models as .Models.
+func (c *Config) Context(answers map[string]string, features map[string]bool) map[string]any {
+	variables := make(map[string]Variable, len(c.Variables))
+	for _, variable := range c.Variables {
+		variables[variable.Name] = variable
+	}
+	data := make(map[string]any, len(answers)+2)
+	scoped := make(map[string]any, len(answers)+1)
+	for name, answer := range answers {
+		value := variables[name].Value(answer)
+		data[name] = value
+		scoped[name] = value
+	}
+	selected := make(map[string]bool, len(c.Features))
+	for _, feature := range c.Features {
+		selected[feature.Name] = features[feature.Name]
+	}
+	data["Features"] = selected
+	scoped["Features"] = selected
+	data["Models"] = c.Models
+	scoped["Models"] = c.Models
+	if c.Namespace != "" {
+		data[c.Namespace] = scoped
+	}
+	return data
+}
+
+// Validate reports problems in the manifest that would make it unusable.
+func (c *Config) Validate() error {
+	if c.Schema > SchemaVersion {
+		return fmt.Errorf("%s: schema %d needs a newer gonew, this one supports up to %d", FileName, c.Schema, SchemaVersion)
+	}
+	if c.Schema < 0 {
+		return fmt.Errorf("%s: invalid schema %d", FileName, c.Schema)
+	}
+
+	if len(c.Delimiters) > 0 {
+		if len(c.Delimiters) != 2 || c.Delimiters[0] == "" || c.Delimiters[1] == "" {
+			return fmt.Errorf("%s: delimiters must be a left and a right delimiter, such as [\"[[\", \"]]\"]", FileName)
+		}
+		if c.Engine != "" && c.Engine != render.DefaultEngine {
+			return fmt.Errorf("%s: delimiters are not supported by the %s engine", FileName, c.Engine)
+		}
+	}
+	left, _ := c.Delims()
+
+	seen := make(map[string]bool, len(c.Variables))
+	for i, variable := range c.Variables {
+		if variable.Name == "" {
+			return fmt.Errorf("%s: variable %d has no name", FileName, i+1)
+		}
+		if seen[variable.Name] {
+			return fmt.Errorf("%s: variable %s is declared more than once", FileName, variable.Name)
+		}
+		seen[variable.Name] = true
+		if variable.From != "" && !cloud.Valid(variable.From) {
+			return fmt.Errorf("%s: variable %s: unknown provider %s, must be one of %v", FileName, variable.Name, variable.From, cloud.Keys())
+		}
+		if _, err := variable.Rule(); err != nil {
+			return fmt.Errorf("%s: variable %s: %v", FileName, variable.Name, err)
+		}
+		if err := variable.checkOptions(left); err != nil {
+			return fmt.Errorf("%s: variable %s: %v", FileName, variable.Name, err)
+		}
+		if variable.ValidateRemote != nil {
+			if err := variable.ValidateRemote.Check(); err != nil {
+				return fmt.Errorf("%s: variable %s: validate_remote: %v", FileName, variable.Name, err)
+			}
+		}
+	}
+
+	for _, variable := range c.Variables {
+		if variable.When == "" {
+			continue
+		}
+		if _, err := expr.Parse(variable.When); err != nil {
+			return fmt.Errorf("%s: variable %s: when: %v", FileName, variable.Name, err)
+		}
+	}
+	if c.Release != nil {
+		if err := c.Release.check(c); err != nil {
+			return fmt.Errorf("%s: release: %v", FileName, err)
+		}
+	}
+	if c.ComposeReadme != nil {
+		if err := c.ComposeReadme.check(); err != nil {
+			return fmt.Errorf("%s: compose_readme: %v", FileName, err)
+		}
+	}
+	for _, hook := range c.Hooks.PostInit {
+		if err := hook.check(); err != nil {
+			return fmt.Errorf("%s: hooks.post_init: %v", FileName, err)
+		}
+	}
+	for _, hook := range c.Hooks.PreInit {
+		if err := hook.check(); err != nil {
+			return fmt.Errorf("%s: hooks.pre_init: %v", FileName, err)
+		}
+	}
+	for i, file := range c.Files {
+		if file.Glob == "" || file.When == "" {
+			return fmt.Errorf("%s: files entry %d needs a glob and a when condition", FileName, i+1)
+		}
+		if _, err := expr.Parse(file.When); err != nil {
+			return fmt.Errorf("%s: files %s: when: %v", FileName, file.Glob, err)
+		}
+	}
+
+	for i, constraint := range c.Constraints {
+		if _, err := expr.Parse(constraint.Expr); err != nil {
+			return fmt.Errorf("%s: constraint %d: %v", FileName, i+1, err)
+		}
+	}
+
+	switch c.Pipeline {
+	case "", PipelineRewriteFirst, PipelineRenderFirst:
+	default:
+		return fmt.Errorf("%s: unknown pipeline %q, must be rewrite_first or render_first", FileName, c.Pipeline)
+	}
+
+	if err := c.Destination.Check(); err != nil {
+		return fmt.Errorf("%s: destination: %v", FileName, err)
+	}
+
+	if !ValidState(c.State) {
+		return fmt.Errorf("%s: unknown state %q, must be experimental, stable, deprecated or blocked", FileName, c.State)
+	}
+
+	if c.Catalog != nil {
+		if _, err := catalog.Lookup(c.Catalog.Provider); err != nil {
+			return fmt.Errorf("%s: catalog: %v", FileName, err)
+		}
+	}
+
+	if c.I18n.Catalogs != "" {
+		if err := safepath.CheckRel(filepath.FromSlash(c.I18n.Catalogs)); err != nil {
+			return fmt.Errorf("%s: i18n: catalogs: %v", FileName, err)
+		}
+	}
+
+	features := make(map[string]bool, len(c.Features))
+	for i, feature := range c.Features {
+		if feature.Name == "" {
+			return fmt.Errorf("%s: feature %d has no name", FileName, i+1)
+		}
+		if features[feature.Name] {
+			return fmt.Errorf("%s: feature %s is declared more than once", FileName, feature.Name)
+		}
+		features[feature.Name] = true
+	}
+
+	if err := checkModels(c.Models); err != nil {
+		return fmt.Errorf("%s: %v", FileName, err)
+	}
+
+	for i, m := range c.Generate.Matrix {
+		if m.Variable == "" || len(m.Files) == 0 {
+			return fmt.Errorf("%s: generate.matrix %d needs files and a variable", FileName, i+1)
+		}
+	}
+
+	if c.Root != "" {
+		if err := safepath.CheckRel(filepath.FromSlash(c.Root)); err != nil {
+			return fmt.Errorf("%s: root: %v", FileName, err)
+		}
+	}
+
+	for from, to := range c.Restore {
+		for _, rel := range []string{from, to} {
+			if err := safepath.CheckRel(filepath.FromSlash(rel)); err != nil || rel == "" {
+				return fmt.Errorf("%s: restore %s: invalid path %q", FileName, from, rel)
+			}
+		}
+	}
+
+	for _, v := range c.GoVersions {
+		if !version.IsValid("go" + v) {
+			return fmt.Errorf("%s: go_versions: invalid Go version %q", FileName, v)
+		}
+	}
+
+	for _, source := range c.Imports {
+		mod, _, _ := strings.Cut(source, "@")
+		if err := module.CheckPath(mod); err != nil {
+			return fmt.Errorf("%s: imports: %v", FileName, err)
+		}
+	}
+
+	if c.Readme != "" && c.Readme != ReadmeDisplay && c.Readme != ReadmeWrite {
+		return fmt.Errorf("%s: readme must be %s or %s", FileName, ReadmeDisplay, ReadmeWrite)
+	}
+
+	if err := normalize.CheckLineEndings(c.Normalize.LineEndings); err != nil {
+		return fmt.Errorf("%s: %v", FileName, err)
+	}
+
+	for i, formatter := range c.Formatters {
+		if formatter.Glob == "" || strings.TrimSpace(formatter.Command) == "" {
+			return fmt.Errorf("%s: formatter %d needs a glob and a command", FileName, i+1)
+		}
+	}
+
+	for i, processor := range c.PostProcess {
+		if processor.Glob == "" || strings.TrimSpace(processor.Command) == "" {
+			return fmt.Errorf("%s: post processor %d needs a glob and a command", FileName, i+1)
+		}
+	}
+
+	for i, artifact := range c.Artifacts {
+		if artifact.Name == "" {
+			return fmt.Errorf("%s: artifact %d has no name", FileName, i+1)
+		}
+	}
+
+	if err := c.validateMigrations(); err != nil {
+		return err
+	}
+
+	layouts := make(map[string]bool, len(c.Layouts))
+	for i, layout := range c.Layouts {
+		if layout.Name == "" {
+			return fmt.Errorf("%s: layout %d has no name", FileName, i+1)
+		}
+		if layouts[layout.Name] {
+			return fmt.Errorf("%s: layout %s is declared more than once", FileName, layout.Name)
+		}
+		layouts[layout.Name] = true
+		for from, to := range layout.Paths {
+			if !strings.HasSuffix(from, "/") || (to != "" && !strings.HasSuffix(to, "/")) {
+				return fmt.Errorf("%s: layout %s: path prefixes must end with a slash", FileName, layout.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Import adds the variables of an imported manifest that c does not declare
+// itself, before its own variables.
+func (c *Config) Import(imported *Config) {
+	declared := make(map[string]bool, len(c.Variables))
+	for _, variable := range c.Variables {
+		declared[variable.Name] = true
+	}
+	var shared []Variable
+	for _, variable := range imported.Variables {
+		if !declared[variable.Name] {
+			declared[variable.Name] = true
+			shared = append(shared, variable)
+		}
+	}
+	c.Variables = append(shared, c.Variables...)
+}
+
+// Restored returns the path rel is generated at after the restore renames,
+// replacing its longest matching leading path elements.
+func (c *Config) Restored(rel string) string {
+	var from string
+	for prefix := range c.Restore {
+		if (rel == prefix || strings.HasPrefix(rel, prefix+"/")) && len(prefix) > len(from) {
+			from = prefix
+		}
+	}
+	if from == "" {
+		return rel
+	}
+	return c.Restore[from] + strings.TrimPrefix(rel, from)
+}
+
+// Layout returns the layout called name, or nil if the template has none by that name.
+func (c *Config) Layout(name string) *Layout {
+	for i := range c.Layouts {
+		if c.Layouts[i].Name == name {
+			return &c.Layouts[i]
+		}
+	}
+	return nil
+}
+
+// CheckConstraints reports the constraints the answers and selected features
+// fail, as one error listing their messages. Expressions refer to answers by
+// variable name and to features as Features.<name>.
+func (c *Config) CheckConstraints(answers map[string]string, features map[string]bool) error {
+	vars := c.Vars(answers, features)
+	var failed []string
+	for _, constraint := range c.Constraints {
+		e, err := expr.Parse(constraint.Expr)
+		if err != nil {
+			return err
+		}
+		ok, err := e.Bool(vars)
+		if err != nil {
+			return fmt.Errorf("constraint %v", err)
+		}
+		if !ok {
+			message := constraint.Message
+			if message == "" {
+				message = "answers do not satisfy " + constraint.Expr
+			}
+			failed = append(failed, message)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("invalid answers:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// Vars returns the variables of the expressions of the manifest: the
+// answers, converted to the types of their variables as templates see them,
+// and the selection of features as Features.<name>.
+func (c *Config) Vars(answers map[string]string, features map[string]bool) expr.Vars {
+	return func(name string) (any, bool) {
+		if feature, ok := strings.CutPrefix(name, "Features."); ok {
+			return features[feature], true
+		}
+		answer, ok := answers[name]
+		if !ok {
+			return nil, false
+		}
+		for _, variable := range c.Variables {
+			if variable.Name == name {
+				return variable.Value(answer), true
+			}
+		}
+		return answer, true
+	}
+}
+
+// Skipped reports whether the file or directory at rel, a slash-separated
+// path relative to the template root, is left out because the condition of
+// a files entry matching it does not hold.
+func (c *Config) Skipped(rel string, answers map[string]string, features map[string]bool) (bool, error) {
+	for _, file := range c.Files {
+		if !glob.Match(file.Glob, rel) {
+			continue
+		}
+		e, err := expr.Parse(file.When)
+		if err != nil {
+			return false, err
+		}
+		ok, err := e.Bool(c.Vars(answers, features))
+		if err != nil {
+			return false, fmt.Errorf("files %s: when %v", file.Glob, err)
+		}
+		if !ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Excluded reports whether the file at rel, a slash-separated path relative
+// to the template root, is ignored or belongs to a feature that is not
+// selected.
+func (c *Config) Excluded(rel string, features map[string]bool) bool {
+	if c.Ignored(rel) {
+		return true
+	}
+	for _, feature := range c.Features {
+		if !features[feature.Name] && glob.MatchAny(feature.Files, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ignored reports whether the file at rel, a slash-separated path relative
+// to the template root, is never generated whatever the answers, such as
+// snippets only read through includeFile, message catalogs or the files of
+// .gonew.
+func (c *Config) Ignored(rel string) bool {
+	if strings.HasPrefix(rel, ".gonew/") {
+		return true
+	}
+	if c.I18n.Catalogs != "" && strings.HasPrefix(rel, strings.TrimSuffix(c.I18n.Catalogs, "/")+"/") {
+		return true
+	}
+	return glob.MatchAny(c.Ignore, rel)
+}
+
+// Conditional reports whether the file or directory at rel, a
+// slash-separated path relative to the template root, is only generated for
+// some answers: it belongs to a feature or matches a files entry with a
+// condition.
+func (c *Config) Conditional(rel string) bool {
+	for _, feature := range c.Features {
+		if glob.MatchAny(feature.Files, rel) {
+			return true
+		}
+	}
+	for _, file := range c.Files {
+		if file.When != "" && glob.Match(file.Glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse decodes a template manifest.
+func Parse(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Load reads and decodes the template manifest at filename.
+func Load(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Find loads the manifest of the template in dir. It reads template.yaml and
+// falls back to translating a cookiecutter.json. The returned data is the
+// manifest in template.yaml form, suitable for caching.
+func Find(dir string) (*Config, []byte, error) {
+	return find(func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, name))
+	})
+}
+
+// FindFS is like Find for the template at the root of fsys, such as a
+// module zip read from a proxy.
+func FindFS(fsys fs.FS) (*Config, []byte, error) {
+	return find(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+func find(readFile func(name string) ([]byte, error)) (*Config, []byte, error) {
+	data, err := readFile(FileName)
+	if err == nil {
+		config, err := Parse(data)
+		return config, data, err
+	}
+	if !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	cookiecutter, cerr := readFile(CookiecutterFileName)
+	if cerr != nil {
+		// Report the missing template.yaml, the primary manifest.
+		return nil, nil, err
+	}
+	config, err := FromCookiecutter(cookiecutter)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = yaml.Marshal(config)
+	return config, data, err
 }
@@ -0,0 +1,28 @@
+package project
+
+import "testing"
+
+func TestVarsTyped(t *testing.T) {
+	config := &Config{
+		Variables: []Variable{{Name: "UseDatabase", Type: "bool"}, {Name: "Port", Type: "port"}, {Name: "Name"}},
+	}
+	dsn := Variable{Name: "DSN", When: "UseDatabase == true"}
+	for answer, want := range map[string]bool{
+		"true": true, "1": true, "TRUE": true, "True": true, "t": true,
+		"false": false, "0": false, "F": false,
+	} {
+		asked, err := dsn.Asked(config.Vars(map[string]string{"UseDatabase": answer}, nil))
+		if err != nil || asked != want {
+			t.Errorf("UseDatabase = %q: Asked = %v, %v, want %v", answer, asked, err, want)
+		}
+	}
+
+	answers := map[string]string{"UseDatabase": "1", "Port": "8080", "Name": "1"}
+	config.Constraints = []Constraint{{Expr: "Port == 8080 && Name == 1 && Name != true && Features.metrics"}}
+	if err := config.CheckConstraints(answers, map[string]bool{"metrics": true}); err != nil {
+		t.Error(err)
+	}
+	if err := config.CheckConstraints(answers, nil); err == nil {
+		t.Error("CheckConstraints accepted a feature that is not selected")
+	}
+}
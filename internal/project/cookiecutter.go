@@ -0,0 +1,70 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CookiecutterFileName is the manifest file of a cookiecutter template.
+const CookiecutterFileName = "cookiecutter.json"
+
+// FromCookiecutter translates a cookiecutter.json file into a Config. The keys
+// become variables in file order, their values become defaults, and answers
+// are exposed to the pongo2 engine under the "cookiecutter" namespace so that
+// {{ cookiecutter.var }} expressions work unchanged.
+func FromCookiecutter(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("%s: expected a JSON object", CookiecutterFileName)
+	}
+
+	config := &Config{
+		Engine:       "pongo2",
+		Namespace:    "cookiecutter",
+		Cookiecutter: true,
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", CookiecutterFileName, err)
+		}
+		name := token.(string)
+
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", CookiecutterFileName, name, err)
+		}
+
+		// Private and copy-only keys configure cookiecutter itself.
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		variable := Variable{Name: name, Placeholder: name}
+		switch value := value.(type) {
+		case string:
+			variable.Default = value
+		case bool:
+			variable.Default = fmt.Sprint(value)
+		case float64:
+			variable.Default = fmt.Sprint(value)
+		case []any:
+			// A list is a choice whose first element is the default.
+			choices := make([]string, len(value))
+			for i, choice := range value {
+				choices[i] = fmt.Sprint(choice)
+			}
+			if len(choices) > 0 {
+				variable.Default = choices[0]
+			}
+			variable.Placeholder = fmt.Sprintf("%s (%s)", name, strings.Join(choices, ", "))
+		default:
+			// Dictionaries are not supported as prompts.
+			continue
+		}
+		config.Variables = append(config.Variables, variable)
+	}
+	return config, nil
+}
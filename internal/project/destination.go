@@ -0,0 +1,91 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Destination is a policy on the module paths projects are generated at,
+// such as an organization's rule that services live under
+// github.com/acme/services/ and are named in kebab case. Prefixes are the
+// allowed module path prefixes, matched at path element boundaries, and
+// Pattern a regular expression the whole module path must match. MinDepth
+// and MaxDepth bound the number of path elements, zero meaning no bound.
+// Message explains the policy to users whose module path breaks it.
+type Destination struct {
+	Prefixes []string `yaml:"prefixes"`
+	Pattern  string   `yaml:"pattern"`
+	MinDepth int      `yaml:"min_depth"`
+	MaxDepth int      `yaml:"max_depth"`
+	Message  string   `yaml:"message"`
+}
+
+// Check reports problems in the declaration of d.
+func (d *Destination) Check() error {
+	if _, err := d.pattern(); err != nil {
+		return fmt.Errorf("pattern: %v", err)
+	}
+	if d.MinDepth < 0 || d.MaxDepth < 0 {
+		return fmt.Errorf("depths must not be negative")
+	}
+	if d.MaxDepth > 0 && d.MinDepth > d.MaxDepth {
+		return fmt.Errorf("min_depth %d is greater than max_depth %d", d.MinDepth, d.MaxDepth)
+	}
+	for _, prefix := range d.Prefixes {
+		if strings.Trim(prefix, "/") == "" {
+			return fmt.Errorf("empty prefix")
+		}
+	}
+	return nil
+}
+
+// pattern compiles Pattern anchored at both ends, or returns nil without one.
+func (d *Destination) pattern() (*regexp.Regexp, error) {
+	if d.Pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(`^(?:` + d.Pattern + `)$`)
+}
+
+// Allow reports why mod breaks the policy, or nil if it does not.
+func (d *Destination) Allow(mod string) error {
+	err := d.allow(mod)
+	if err != nil && d.Message != "" {
+		return fmt.Errorf("%v: %s", err, d.Message)
+	}
+	return err
+}
+
+func (d *Destination) allow(mod string) error {
+	if len(d.Prefixes) > 0 {
+		allowed := false
+		for _, prefix := range d.Prefixes {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if mod == prefix || strings.HasPrefix(mod, prefix+"/") {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("module path %s must start with %s", mod, strings.Join(d.Prefixes, " or "))
+		}
+	}
+
+	depth := strings.Count(mod, "/") + 1
+	if d.MinDepth > 0 && depth < d.MinDepth {
+		return fmt.Errorf("module path %s has %d elements, at least %d are required", mod, depth, d.MinDepth)
+	}
+	if d.MaxDepth > 0 && depth > d.MaxDepth {
+		return fmt.Errorf("module path %s has %d elements, at most %d are allowed", mod, depth, d.MaxDepth)
+	}
+
+	re, err := d.pattern()
+	if err != nil {
+		return err
+	}
+	if re != nil && !re.MatchString(mod) {
+		return fmt.Errorf("module path %s does not match %s", mod, d.Pattern)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Migration translates answers recorded for an older version of a template,
+// so that they can be reused without prompting for everything again.
+// Migrations are applied in order and only touch answers that are present,
+// which makes applying them to already migrated answers harmless.
+type Migration struct {
+	// Rename maps old variable names to new ones.
+	Rename map[string]string `yaml:"rename"`
+	// Split divides the answer of one variable into several.
+	Split []Split `yaml:"split"`
+	// Defaults answers variables introduced since, unless already answered.
+	Defaults map[string]string `yaml:"defaults"`
+}
+
+// Split divides the answer of From at Separator into the variables of Into.
+// Parts missing from the answer leave their variables to be prompted for.
+type Split struct {
+	From      string   `yaml:"from"`
+	Separator string   `yaml:"separator"`
+	Into      []string `yaml:"into"`
+}
+
+// Migrate returns answers with the variable migrations of the template applied.
+func (c *Config) Migrate(answers map[string]string) map[string]string {
+	migrated := make(map[string]string, len(answers))
+	for name, value := range answers {
+		migrated[name] = value
+	}
+
+	for _, migration := range c.VariableMigrations {
+		// Take every old answer out first, so renames within one
+		// migration do not depend on each other.
+		renamed := make(map[string]string, len(migration.Rename))
+		for from, to := range migration.Rename {
+			if value, ok := migrated[from]; ok {
+				renamed[to] = value
+				delete(migrated, from)
+			}
+		}
+		for name, value := range renamed {
+			if _, ok := migrated[name]; !ok {
+				migrated[name] = value
+			}
+		}
+
+		for _, split := range migration.Split {
+			value, ok := migrated[split.From]
+			if !ok {
+				continue
+			}
+			delete(migrated, split.From)
+			for i, part := range strings.SplitN(value, split.Separator, len(split.Into)) {
+				if _, ok := migrated[split.Into[i]]; !ok {
+					migrated[split.Into[i]] = part
+				}
+			}
+		}
+
+		for name, value := range migration.Defaults {
+			if _, ok := migrated[name]; !ok {
+				migrated[name] = value
+			}
+		}
+	}
+	return migrated
+}
+
+// validateMigrations reports migrations that cannot be applied.
+func (c *Config) validateMigrations() error {
+	for i, migration := range c.VariableMigrations {
+		for from, to := range migration.Rename {
+			if from == "" || to == "" {
+				return fmt.Errorf("%s: variable migration %d: renames need both names", FileName, i+1)
+			}
+		}
+		for _, split := range migration.Split {
+			if split.From == "" || split.Separator == "" || len(split.Into) == 0 {
+				return fmt.Errorf("%s: variable migration %d: splits need from, separator and into", FileName, i+1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package project
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Model is an entity of the project, such as User or Order, from which
+// CRUD-style templates generate repositories, handlers, migrations and
+// tests. The models are available to templates as .Models, and a
+// generate.matrix entry with the variable Models stamps out files per model.
+type Model struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Fields      []Field `yaml:"fields"`
+}
+
+// Field is an attribute of a model. Type is free-form, such as a Go type or
+// a column type, for templates to map as they see fit.
+type Field struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Unique      bool   `yaml:"unique"`
+	// Default is the value of the field when none is given.
+	Default string `yaml:"default"`
+}
+
+// LoadModels reads the models of a user-provided models file, a YAML
+// document with a models list as in template.yaml. They replace the models
+// of the template.
+func LoadModels(filename string) ([]Model, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Models []Model `yaml:"models"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := checkModels(file.Models); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return file.Models, nil
+}
+
+// checkModels reports models and fields without a name or declared twice.
+func checkModels(models []Model) error {
+	seen := make(map[string]bool, len(models))
+	for i, model := range models {
+		if model.Name == "" {
+			return fmt.Errorf("model %d has no name", i+1)
+		}
+		if seen[model.Name] {
+			return fmt.Errorf("model %s is declared more than once", model.Name)
+		}
+		seen[model.Name] = true
+
+		fields := make(map[string]bool, len(model.Fields))
+		for j, field := range model.Fields {
+			if field.Name == "" {
+				return fmt.Errorf("model %s: field %d has no name", model.Name, j+1)
+			}
+			if fields[field.Name] {
+				return fmt.Errorf("model %s: field %s is declared more than once", model.Name, field.Name)
+			}
+			fields[field.Name] = true
+		}
+	}
+	return nil
+}
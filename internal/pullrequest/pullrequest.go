@@ -0,0 +1,134 @@
+// Package pullrequest opens pull requests, or merge requests, on GitHub and
+// GitLab for branches pushed by gonew.
+package pullrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Providers.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Request is a pull request to open on the repository at Host/Repo, such as
+// github.com and org/repo, from branch Head into Base. API overrides the
+// base URL of the provider's API derived from Host.
+type Request struct {
+	API   string
+	Host  string
+	Repo  string
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Token string
+}
+
+// Detect guesses the provider hosting host: gitlab for hosts naming GitLab,
+// github otherwise.
+func Detect(host string) string {
+	if strings.Contains(host, "gitlab") {
+		return GitLab
+	}
+	return GitHub
+}
+
+// Open opens the pull request with provider and returns its web URL.
+func Open(ctx context.Context, client *http.Client, provider string, r Request) (string, error) {
+	switch provider {
+	case GitHub:
+		return openGitHub(ctx, client, r)
+	case GitLab:
+		return openGitLab(ctx, client, r)
+	}
+	return "", fmt.Errorf("unknown pull request provider %q, must be github or gitlab", provider)
+}
+
+func openGitHub(ctx context.Context, client *http.Client, r Request) (string, error) {
+	api := r.API
+	switch {
+	case api != "":
+	case r.Host == "github.com":
+		api = "https://api.github.com"
+	default:
+		// GitHub Enterprise Server.
+		api = "https://" + r.Host + "/api/v3"
+	}
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+	}
+	err := post(ctx, client, strings.TrimSuffix(api, "/")+"/repos/"+r.Repo+"/pulls", map[string]string{
+		"title": r.Title,
+		"head":  r.Head,
+		"base":  r.Base,
+		"body":  r.Body,
+	}, map[string]string{
+		"Authorization": "Bearer " + r.Token,
+		"Accept":        "application/vnd.github+json",
+	}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("github: %v", err)
+	}
+	return resp.HTMLURL, nil
+}
+
+func openGitLab(ctx context.Context, client *http.Client, r Request) (string, error) {
+	var resp struct {
+		WebURL string `json:"web_url"`
+	}
+	api := r.API
+	if api == "" {
+		api = "https://" + r.Host + "/api/v4"
+	}
+	endpoint := strings.TrimSuffix(api, "/") + "/projects/" + url.PathEscape(r.Repo) + "/merge_requests"
+	err := post(ctx, client, endpoint, map[string]string{
+		"source_branch": r.Head,
+		"target_branch": r.Base,
+		"title":         r.Title,
+		"description":   r.Body,
+	}, map[string]string{
+		"PRIVATE-TOKEN": r.Token,
+	}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("gitlab: %v", err)
+	}
+	return resp.WebURL, nil
+}
+
+// post sends body as JSON to endpoint and decodes the response into v.
+func post(ctx context.Context, client *http.Client, endpoint string, body any, headers map[string]string, v any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s: %s", endpoint, resp.Status, bytes.TrimSpace(data))
+	}
+	return json.Unmarshal(data, v)
+}
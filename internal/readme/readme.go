@@ -0,0 +1,124 @@
+// Package readme composes the README of a generated project from the parts
+// a template declares, so that small templates get a useful README without
+// maintaining a templated one.
+package readme
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FileName is the name of the composed README in the generated project.
+const FileName = "README.md"
+
+// Readme is the content of a composed README.
+type Readme struct {
+	Title          string
+	Description    string
+	Badges         []Badge
+	GettingStarted []string
+	Sections       []Section
+}
+
+// Badge is a status image linking to a service, such as the documentation
+// of the module.
+type Badge struct {
+	Alt   string
+	Image string
+	Link  string
+}
+
+// Section is a part of the README under its own heading, Body being
+// Markdown.
+type Section struct {
+	Title string
+	Body  string
+}
+
+// builtins are the badges of well-known services for a module path. Those
+// for GitHub services need a github.com module path.
+var builtins = map[string]func(module string) (Badge, error){
+	"go-reference": func(module string) (Badge, error) {
+		return Badge{"Go Reference", "https://pkg.go.dev/badge/" + module + ".svg", "https://pkg.go.dev/" + module}, nil
+	},
+	"go-report-card": func(module string) (Badge, error) {
+		return Badge{"Go Report Card", "https://goreportcard.com/badge/" + module, "https://goreportcard.com/report/" + module}, nil
+	},
+	"github-actions": func(module string) (Badge, error) {
+		repo, err := githubRepo(module)
+		if err != nil {
+			return Badge{}, err
+		}
+		return Badge{"CI", "https://" + repo + "/actions/workflows/ci.yml/badge.svg", "https://" + repo + "/actions"}, nil
+	},
+	"codecov": func(module string) (Badge, error) {
+		repo, err := githubRepo(module)
+		if err != nil {
+			return Badge{}, err
+		}
+		slug := strings.TrimPrefix(repo, "github.com/")
+		return Badge{"Coverage", "https://codecov.io/gh/" + slug + "/graph/badge.svg", "https://codecov.io/gh/" + slug}, nil
+	},
+}
+
+// githubRepo returns the github.com/org/repo prefix of module.
+func githubRepo(module string) (string, error) {
+	parts := strings.SplitN(module, "/", 4)
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", fmt.Errorf("needs a github.com module path, not %s", module)
+	}
+	return strings.Join(parts[:3], "/"), nil
+}
+
+// Builtins returns the names of the built-in badges.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Builtin returns the built-in badge called name for the module at path
+// module.
+func Builtin(name, module string) (Badge, error) {
+	badge, ok := builtins[name]
+	if !ok {
+		return Badge{}, fmt.Errorf("unknown badge %s, must be one of %s", name, strings.Join(Builtins(), ", "))
+	}
+	b, err := badge(module)
+	if err != nil {
+		return Badge{}, fmt.Errorf("badge %s: %v", name, err)
+	}
+	return b, nil
+}
+
+// Markdown returns the README as Markdown.
+func (r *Readme) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", r.Title)
+
+	if len(r.Badges) > 0 {
+		badges := make([]string, len(r.Badges))
+		for i, b := range r.Badges {
+			badges[i] = fmt.Sprintf("[![%s](%s)](%s)", b.Alt, b.Image, b.Link)
+		}
+		fmt.Fprintf(&buf, "\n%s\n", strings.Join(badges, " "))
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&buf, "\n%s\n", r.Description)
+	}
+	if len(r.GettingStarted) > 0 {
+		fmt.Fprintf(&buf, "\n## Getting started\n\n```shell\n%s\n```\n", strings.Join(r.GettingStarted, "\n"))
+	}
+	for _, section := range r.Sections {
+		fmt.Fprintf(&buf, "\n## %s\n", section.Title)
+		if section.Body != "" {
+			fmt.Fprintf(&buf, "\n%s\n", section.Body)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,217 @@
+// Package registry reads and writes the template index, a YAML document
+// listing published templates and their versions.
+package registry
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/betterde/gonew/internal/lockfile"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Index is the list of templates known to a registry.
+type Index struct {
+	Templates []*Entry `yaml:"templates"`
+}
+
+// Entry describes a published template.
+type Entry struct {
+	Name     string     `yaml:"name"`
+	Module   string     `yaml:"module"`
+	Desc     string     `yaml:"desc"`
+	Latest   string     `yaml:"latest"`
+	Versions []*Version `yaml:"versions"`
+	// State overrides the lifecycle state declared by the template's
+	// manifest, and Replacement the template suggested instead.
+	State       string `yaml:"state,omitempty"`
+	Replacement string `yaml:"replacement,omitempty"`
+	// Generations counts the projects generated from the template, and
+	// LastGenerated is when the latest was, as reported by users who opt
+	// in to analytics.
+	Generations   int       `yaml:"generations,omitempty"`
+	LastGenerated time.Time `yaml:"last_generated,omitempty"`
+}
+
+// Version is a single published version of a template.
+type Version struct {
+	Version   string    `yaml:"version"`
+	Published time.Time `yaml:"published"`
+	Notes     string    `yaml:"notes"`
+	// Generations counts the projects generated from this version.
+	Generations int `yaml:"generations,omitempty"`
+}
+
+// Load reads the index at filename. A missing file is an empty index.
+func Load(filename string) (*Index, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{}
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// Open reads the index at source, a file path or an http(s) URL, in YAML
+// or JSON. Unlike with Load, the index must exist.
+func Open(source string) (*Index, error) {
+	var data []byte
+	var err error
+	if IsURL(source) {
+		data, err = fetch(source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(source, data)
+}
+
+// IsURL reports whether source, an index location, is an http(s) URL rather
+// than a file path.
+func IsURL(source string) bool {
+	u, err := url.Parse(source)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// fetch returns the index served at url.
+func fetch(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize))
+}
+
+// maxRemoteSize bounds the size of indexes fetched over HTTP.
+const maxRemoteSize = 8 << 20
+
+//go:embed default.yaml
+var defaultIndex []byte
+
+// Default returns the index built into gonew, listing well-known templates
+// without their versions.
+func Default() (*Index, error) {
+	return parse("default index", defaultIndex)
+}
+
+func parse(name string, data []byte) (*Index, error) {
+	index := &Index{}
+	if err := yaml.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return index, nil
+}
+
+// Updated returns when the latest version of the template was published,
+// the zero time if the index does not know.
+func (e *Entry) Updated() time.Time {
+	for _, v := range e.Versions {
+		if v.Version == e.Latest {
+			return v.Published
+		}
+	}
+	return time.Time{}
+}
+
+// Save writes the index to filename, replacing it atomically.
+func (i *Index) Save(filename string) error {
+	data, err := yaml.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return lockfile.WriteFile(filename, data, 0644)
+}
+
+// Update loads the index at filename, applies fn and saves the result,
+// holding a lock throughout so concurrent updates are not lost.
+func Update(filename string, fn func(*Index) error) error {
+	return lockfile.Update(filename, func() error {
+		index, err := Load(filename)
+		if err != nil {
+			return err
+		}
+		if err := fn(index); err != nil {
+			return err
+		}
+		return index.Save(filename)
+	})
+}
+
+// Lookup returns the entry for the template module mod, or nil.
+func (i *Index) Lookup(mod string) *Entry {
+	for _, entry := range i.Templates {
+		if entry.Module == mod {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Publish records version of the template module mod, creating its entry
+// if needed and refreshing its name and description.
+func (i *Index) Publish(mod, name, desc string, version *Version) *Entry {
+	entry := i.Lookup(mod)
+	if entry == nil {
+		entry = &Entry{Module: mod}
+		i.Templates = append(i.Templates, entry)
+		sort.Slice(i.Templates, func(a, b int) bool {
+			return i.Templates[a].Module < i.Templates[b].Module
+		})
+	}
+	entry.Name = name
+	entry.Desc = desc
+
+	versions := entry.Versions[:0]
+	for _, v := range entry.Versions {
+		if v.Version != version.Version {
+			versions = append(versions, v)
+		}
+	}
+	entry.Versions = append(versions, version)
+	sort.Slice(entry.Versions, func(a, b int) bool {
+		return semver.Compare(entry.Versions[a].Version, entry.Versions[b].Version) > 0
+	})
+	entry.Latest = entry.Versions[0].Version
+	return entry
+}
+
+// RecordGeneration counts a project generated at t from version of the
+// template module mod. Only templates in the index are counted, so that
+// the index learns nothing about others, and it reports whether mod was.
+func (i *Index) RecordGeneration(mod, version string, t time.Time) bool {
+	entry := i.Lookup(mod)
+	if entry == nil {
+		return false
+	}
+	entry.Generations++
+	if t.After(entry.LastGenerated) {
+		entry.LastGenerated = t
+	}
+	for _, v := range entry.Versions {
+		if v.Version == version {
+			v.Generations++
+		}
+	}
+	return true
+}
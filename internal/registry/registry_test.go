@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testIndex = `templates:
+  - name: service
+    module: example.com/tpl/service
+    latest: v1.1.0
+    state: deprecated
+    versions:
+      - version: v1.1.0
+        published: 2026-01-02T00:00:00Z
+      - version: v1.0.0
+        published: 2025-06-01T00:00:00Z
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	index, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil || len(index.Templates) != 0 {
+		t.Errorf("Load of a missing file = %+v, %v, want an empty index", index, err)
+	}
+
+	filename := filepath.Join(dir, "index.yaml")
+	if err := os.WriteFile(filename, []byte(testIndex), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index, err = Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := index.Lookup("example.com/tpl/service")
+	if entry == nil || entry.State != "deprecated" || len(entry.Versions) != 2 {
+		t.Fatalf("Lookup = %+v", entry)
+	}
+	if want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC); !entry.Updated().Equal(want) {
+		t.Errorf("Updated = %v, want %v", entry.Updated(), want)
+	}
+	if index.Lookup("example.com/tpl/other") != nil {
+		t.Error("Lookup found a template not in the index")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testIndex))
+	}))
+	defer server.Close()
+
+	index, err := Open(server.URL + "/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Lookup("example.com/tpl/service") == nil {
+		t.Error("index served over HTTP lacks its template")
+	}
+	if _, err := Open(server.URL + "/missing.yaml"); err == nil {
+		t.Error("Open of a missing URL succeeded")
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Open of a missing file succeeded")
+	}
+
+	filename := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(filename, []byte(`{"templates": [{"name": "cli", "module": "example.com/tpl/cli"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if index, err := Open(filename); err != nil || index.Lookup("example.com/tpl/cli") == nil {
+		t.Errorf("Open of a JSON index = %+v, %v", index, err)
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/index.yaml": true,
+		"http://localhost:8080/index":    true,
+		"index.yaml":                     false,
+		"/srv/index.yaml":                false,
+		"https:index.yaml":               false,
+		"ftp://example.com/index.yaml":   false,
+		`C:\index.yaml`:                  false,
+	}
+	for source, want := range tests {
+		if got := IsURL(source); got != want {
+			t.Errorf("IsURL(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	index, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Templates) == 0 {
+		t.Error("the default index lists no templates")
+	}
+}
+
+func TestPublish(t *testing.T) {
+	index := &Index{}
+	index.Publish("example.com/tpl/b", "b", "", &Version{Version: "v1.0.0"})
+	index.Publish("example.com/tpl/a", "a", "", &Version{Version: "v1.0.0"})
+	index.Publish("example.com/tpl/a", "a", "new", &Version{Version: "v1.2.0"})
+	entry := index.Publish("example.com/tpl/a", "a", "new", &Version{Version: "v1.10.0", Notes: "again"})
+	index.Publish("example.com/tpl/a", "a", "new", &Version{Version: "v1.10.0", Notes: "replaced"})
+
+	if index.Templates[0].Module != "example.com/tpl/a" {
+		t.Errorf("templates are not sorted by module: %s first", index.Templates[0].Module)
+	}
+	if entry.Latest != "v1.10.0" || entry.Desc != "new" || len(entry.Versions) != 3 || entry.Versions[0].Notes != "replaced" {
+		t.Errorf("entry = %+v, versions %+v", entry, entry.Versions)
+	}
+}
+
+func TestRecordGeneration(t *testing.T) {
+	index := &Index{}
+	index.Publish("example.com/tpl/a", "a", "", &Version{Version: "v1.0.0"})
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !index.RecordGeneration("example.com/tpl/a", "v1.0.0", first) {
+		t.Error("RecordGeneration did not count a template of the index")
+	}
+	index.RecordGeneration("example.com/tpl/a", "v0.9.0", first.Add(-time.Hour))
+	if index.RecordGeneration("example.com/tpl/unknown", "v1.0.0", first) {
+		t.Error("RecordGeneration counted a template not in the index")
+	}
+
+	entry := index.Lookup("example.com/tpl/a")
+	if entry.Generations != 2 || entry.Versions[0].Generations != 1 || !entry.LastGenerated.Equal(first) {
+		t.Errorf("entry = %+v, version %+v", entry, entry.Versions[0])
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "index.yaml")
+	if err := os.WriteFile(filename, []byte(testIndex), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(filename, func(index *Index) error {
+				index.RecordGeneration("example.com/tpl/service", "v1.1.0", time.Now())
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	index, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := index.Lookup("example.com/tpl/service").Generations; got != n {
+		t.Errorf("Generations = %d after %d concurrent updates", got, n)
+	}
+}
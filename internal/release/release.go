@@ -0,0 +1,115 @@
+// Package release generates the files a project needs to be versioned and
+// released: a changelog, a version package and the configuration of a
+// release tool.
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Release tools.
+const (
+	GoReleaser    = "goreleaser"
+	ReleasePlease = "release-please"
+)
+
+// Tools are the supported release tools.
+var Tools = []string{GoReleaser, ReleasePlease}
+
+// VersionFile is the version package of the project.
+const VersionFile = "internal/version/version.go"
+
+// Project is the project the files are generated for.
+type Project struct {
+	// Name is the name of the project, such as the last element of the
+	// module path.
+	Name   string
+	Module string
+	// Main is the package built into the released binary, relative to the
+	// module root, "." if empty.
+	Main string
+}
+
+// Files returns the release files of p for tool by slash-separated path.
+func Files(p Project, tool string) (map[string][]byte, error) {
+	if p.Main == "" {
+		p.Main = "."
+	}
+	files := map[string][]byte{
+		"CHANGELOG.md": changelog(p),
+	}
+	switch tool {
+	case GoReleaser:
+		files[VersionFile] = versionPackage(p, "dev", "")
+		files[".goreleaser.yaml"] = goreleaser(p)
+	case ReleasePlease:
+		files[VersionFile] = versionPackage(p, "0.0.0", " // x-release-please-version")
+		config, err := releasePlease(p)
+		if err != nil {
+			return nil, err
+		}
+		files["release-please-config.json"] = config
+		files[".release-please-manifest.json"] = []byte("{\n  \".\": \"0.0.0\"\n}\n")
+	default:
+		return nil, fmt.Errorf("unknown release tool %s, must be one of %s", tool, strings.Join(Tools, ", "))
+	}
+	return files, nil
+}
+
+func changelog(p Project) []byte {
+	return []byte(fmt.Sprintf(`# Changelog
+
+All notable changes to %s are documented in this file.
+
+## Unreleased
+`, p.Name))
+}
+
+func versionPackage(p Project, initial, annotation string) []byte {
+	return []byte(fmt.Sprintf(`// Package version reports the version of %[1]s.
+package version
+
+// Version is the version of %[1]s, set when building a release with
+//
+//	-ldflags "-X %[2]s/internal/version.Version=v1.2.3"
+var Version = %[3]q%[4]s
+`, p.Name, p.Module, initial, annotation))
+}
+
+func goreleaser(p Project) []byte {
+	return []byte(fmt.Sprintf(`version: 2
+project_name: %s
+builds:
+  - main: %s
+    env:
+      - CGO_ENABLED=0
+    ldflags:
+      - -s -w -X %s/internal/version.Version={{ .Version }}
+archives:
+  - formats: [tar.gz]
+checksum:
+  name_template: checksums.txt
+changelog:
+  use: git
+`, p.Name, p.Main, p.Module))
+}
+
+func releasePlease(p Project) ([]byte, error) {
+	config := map[string]any{
+		"packages": map[string]any{
+			".": map[string]any{
+				"release-type":   "go",
+				"package-name":   p.Name,
+				"changelog-path": "CHANGELOG.md",
+				"extra-files":    []string{VersionFile},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,144 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/betterde/gonew/internal/safepath"
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltinFuncs returns the functions that do not depend on a template,
+// available to generated variables as well as template files.
+func BuiltinFuncs() map[string]any {
+	return map[string]any{
+		"indent":       indent,
+		"randAlphaNum": randAlphaNum,
+		"randHex":      randHex,
+		"uuidv4":       uuidv4,
+		"bcrypt":       bcryptHash,
+
+		"now":        now,
+		"dateFormat": dateFormat,
+		"dateAdd":    dateAdd,
+		"duration":   duration,
+
+		"semver":        parseSemver,
+		"semverBump":    semverBump,
+		"semverCompare": semverCompare,
+
+		"add": add,
+		"sub": sub,
+		"mul": mul,
+		"div": div,
+		"mod": mod,
+
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"snakeCase": snakeCase,
+		"camelCase": camelCase,
+		"plural":    plural,
+	}
+}
+
+// Funcs returns the functions available to templates rooted at root.
+func Funcs(root string) map[string]any {
+	funcs := BuiltinFuncs()
+	funcs["includeFile"] = includeFile(root)
+	funcs["readYAML"] = readYAML(root)
+	funcs["readJSON"] = readJSON(root)
+	return funcs
+}
+
+// Generate evaluates a generated variable's expression, such as
+// "randAlphaNum 32", with the builtin functions.
+func Generate(name, expr string) (string, error) {
+	out, err := newGoTemplate(BuiltinFuncs(), Options{}).Render(name, "{{ "+expr+" }}", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// includeFile returns a function reading files of the template, such as
+// shared snippets. Paths are relative to root and may not leave it.
+func includeFile(root string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		data, err := readTemplateFile(root, "includeFile", name)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// readYAML returns a function decoding YAML data files of the template,
+// such as lists of regions, paths being resolved as for includeFile.
+func readYAML(root string) func(string) (any, error) {
+	return func(name string) (any, error) {
+		data, err := readTemplateFile(root, "readYAML", name)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("readYAML: %s: %v", name, err)
+		}
+		return v, nil
+	}
+}
+
+// readJSON is readYAML for JSON data files.
+func readJSON(root string) func(string) (any, error) {
+	return func(name string) (any, error) {
+		data, err := readTemplateFile(root, "readJSON", name)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("readJSON: %s: %v", name, err)
+		}
+		return v, nil
+	}
+}
+
+// readTemplateFile reads the file name, relative to root, for the template
+// function fn, refusing paths leading outside of root.
+func readTemplateFile(root, fn, name string) ([]byte, error) {
+	if err := safepath.CheckRel(name); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	resolvedRoot, err := safepath.Resolve(root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := safepath.Resolve(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	if !safepath.Within(resolvedRoot, resolved) {
+		return nil, fmt.Errorf("%s: %s resolves outside of the template", fn, name)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	return data, nil
+}
+
+// indent indents every non-empty line of s by n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
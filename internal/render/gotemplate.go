@@ -0,0 +1,32 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// goTemplate renders files with text/template.
+type goTemplate struct {
+	funcs       template.FuncMap
+	left, right string
+	whitespace  whitespace
+}
+
+func newGoTemplate(funcs map[string]any, opts Options) Engine {
+	left, right := opts.delims()
+	return goTemplate{funcs: funcs, left: left, right: right, whitespace: newWhitespace(opts)}
+}
+
+func (e goTemplate) Render(name, content string, data map[string]any) ([]byte, error) {
+	tmpl, err := template.New(name).Delims(e.left, e.right).Funcs(e.funcs).Parse(e.whitespace.apply(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error executing template %s: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// toNumber returns v as an int when it is integral, or else as a float64.
+// Strings holding numbers are accepted, so untyped answers work too.
+func toNumber(v any) (int, float64, bool, error) {
+	switch v := v.(type) {
+	case int:
+		return v, 0, true, nil
+	case int64:
+		return int(v), 0, true, nil
+	case float64:
+		return 0, v, false, nil
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i, 0, true, nil
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return 0, f, false, nil
+		}
+	}
+	return 0, 0, false, fmt.Errorf("%v is not a number", v)
+}
+
+// arithmetic returns a function applying op to integers, or fop when either
+// operand is a float.
+func arithmetic(op func(a, b int) (int, error), fop func(a, b float64) float64) func(a, b any) (any, error) {
+	return func(a, b any) (any, error) {
+		ai, af, aInt, err := toNumber(a)
+		if err != nil {
+			return nil, err
+		}
+		bi, bf, bInt, err := toNumber(b)
+		if err != nil {
+			return nil, err
+		}
+		if aInt && bInt {
+			return op(ai, bi)
+		}
+		if aInt {
+			af = float64(ai)
+		}
+		if bInt {
+			bf = float64(bi)
+		}
+		return fop(af, bf), nil
+	}
+}
+
+var errDivisionByZero = errors.New("division by zero")
+
+var (
+	add = arithmetic(func(a, b int) (int, error) { return a + b, nil }, func(a, b float64) float64 { return a + b })
+	sub = arithmetic(func(a, b int) (int, error) { return a - b, nil }, func(a, b float64) float64 { return a - b })
+	mul = arithmetic(func(a, b int) (int, error) { return a * b, nil }, func(a, b float64) float64 { return a * b })
+	div = arithmetic(func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errDivisionByZero
+		}
+		return a / b, nil
+	}, func(a, b float64) float64 { return a / b })
+	mod = arithmetic(func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errDivisionByZero
+		}
+		return a % b, nil
+	}, math.Mod)
+)
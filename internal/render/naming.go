@@ -0,0 +1,71 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// words splits an identifier such as "OrderItem", "order_item" or
+// "HTTPServer" into its lower-case words.
+func words(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		// A word starts at an upper-case letter following a lower-case
+		// one, or ending a run of upper-case letters before a lower-case
+		// one, as in "HTTP|Server".
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// snakeCase returns s as snake_case, such as "order_item" for "OrderItem".
+func snakeCase(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+// camelCase returns s as camelCase, such as "orderItem" for "order_item".
+func camelCase(s string) string {
+	w := words(s)
+	for i := 1; i < len(w); i++ {
+		r, size := utf8.DecodeRuneInString(w[i])
+		w[i] = string(unicode.ToUpper(r)) + w[i][size:]
+	}
+	return strings.Join(w, "")
+}
+
+// plural returns the English plural of the noun s, such as "categories"
+// for "category", with the regular rules only.
+func plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
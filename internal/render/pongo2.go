@@ -0,0 +1,97 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/safepath"
+	"github.com/flosch/pongo2/v6"
+)
+
+// pongo2Engine renders files with the Jinja-like syntax of pongo2, which eases
+// migrating templates from cookiecutter and similar tools. Functions are
+// callable from the context, e.g. {{ includeFile("snippets/a.part") }}.
+type pongo2Engine struct {
+	funcs map[string]any
+	set   *pongo2.TemplateSet
+}
+
+func newPongo2(funcs map[string]any, opts Options) Engine {
+	set := pongo2.NewSet("gonew", rootLoader{root: opts.Root})
+	// ssi reads files itself, bypassing the loader.
+	if err := set.BanTag("ssi"); err != nil {
+		panic(err)
+	}
+	set.Options.TrimBlocks = opts.TrimBlocks
+	set.Options.LStripBlocks = opts.LStripBlocks
+	return pongo2Engine{funcs: funcs, set: set}
+}
+
+func (e pongo2Engine) Render(name, content string, data map[string]any) ([]byte, error) {
+	tmpl, err := e.set.FromString(content)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %v", name, err)
+	}
+
+	context := make(pongo2.Context, len(e.funcs)+len(data))
+	for name, fn := range e.funcs {
+		context[name] = fn
+	}
+	for key, value := range data {
+		context[key] = value
+	}
+
+	out, err := tmpl.ExecuteBytes(context)
+	if err != nil {
+		return nil, fmt.Errorf("error executing template %s: %v", name, err)
+	}
+	return out, nil
+}
+
+// rootLoader loads the files of include, import and extends tags from the
+// template rooted at root, refusing paths leading outside of it as
+// includeFile does.
+type rootLoader struct {
+	root string
+}
+
+// Abs resolves name against the directory of the including file base, or
+// against the root for the files being rendered, which have no name.
+func (l rootLoader) Abs(base, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if base == "" {
+		return filepath.Join(l.root, filepath.FromSlash(name))
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(name))
+}
+
+func (l rootLoader) Get(path string) (io.Reader, error) {
+	if l.root == "" {
+		return nil, fmt.Errorf("%s: templates cannot load files here", path)
+	}
+	if !filepath.IsAbs(path) {
+		path = l.Abs("", path)
+	}
+	root, err := safepath.Resolve(l.root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := safepath.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if !safepath.Within(root, resolved) {
+		return nil, fmt.Errorf("%s resolves outside of the template", path)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
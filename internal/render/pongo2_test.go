@@ -0,0 +1,66 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPongo2LoaderConfinement(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "template")
+	if err := os.MkdirAll(filepath.Join(root, "snippets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "snippets", "a.part"), []byte("snippet"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("stolen"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := New("pongo2", nil, Options{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := engine.Render("ok", `{% include "snippets/a.part" %}`, nil)
+	if err != nil {
+		t.Fatalf("include within the template: %v", err)
+	}
+	if string(out) != "snippet" {
+		t.Errorf("include within the template = %q, want %q", out, "snippet")
+	}
+
+	for _, content := range []string{
+		`{% include "../secret" %}`,
+		`{% include "snippets/../../secret" %}`,
+		`{% include "` + filepath.ToSlash(secret) + `" %}`,
+		`{% include "link" %}`,
+		`{% extends "../secret" %}`,
+		`{% import "../secret" x %}`,
+		`{% ssi "` + filepath.ToSlash(secret) + `" %}`,
+		`{% ssi "snippets/a.part" %}`,
+	} {
+		out, err := engine.Render("bad", content, nil)
+		if err == nil {
+			t.Errorf("%s rendered %q, want an error", content, out)
+		} else if strings.Contains(string(out), "stolen") {
+			t.Errorf("%s leaked the file outside of the template", content)
+		}
+	}
+}
+
+func TestPongo2LoaderWithoutRoot(t *testing.T) {
+	engine, err := New("pongo2", nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := engine.Render("bad", `{% include "/etc/hostname" %}`, nil); err == nil {
+		t.Errorf("include without a root rendered %q, want an error", out)
+	}
+}
@@ -0,0 +1,55 @@
+// Package render implements the templating engines used to render template files.
+package render
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultEngine is the engine used when a template does not select one.
+const DefaultEngine = "gotemplate"
+
+// An Engine renders the content of a single template file with the answer context.
+type Engine interface {
+	Render(name, content string, data map[string]any) ([]byte, error)
+}
+
+// factory creates an engine making funcs available to templates.
+type factory func(funcs map[string]any, opts Options) Engine
+
+var engines = map[string]factory{
+	DefaultEngine: newGoTemplate,
+	"pongo2":      newPongo2,
+	"jinja":       newPongo2,
+}
+
+// New returns the engine registered under name, the default engine if name
+// is empty, with funcs available to the templates it renders.
+func New(name string, funcs map[string]any, opts Options) (Engine, error) {
+	if name == "" {
+		name = DefaultEngine
+	}
+	engine, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template engine %q, available engines: %v", name, Names())
+	}
+	if (opts.LeftDelim != "" || opts.RightDelim != "") && name != DefaultEngine {
+		return nil, fmt.Errorf("template engine %q does not support custom delimiters", name)
+	}
+	return engine(funcs, opts), nil
+}
+
+// Lookup returns the engine registered under name without any functions.
+func Lookup(name string) (Engine, error) {
+	return New(name, nil, Options{})
+}
+
+// Names returns the names of the registered engines in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,213 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"", DefaultEngine, "pongo2", "jinja"} {
+		if _, err := New(name, nil, Options{}); err != nil {
+			t.Errorf("New(%q): %v", name, err)
+		}
+	}
+	if _, err := New("mustache", nil, Options{}); err == nil {
+		t.Error("New accepted an unknown engine")
+	}
+	if _, err := New("pongo2", nil, Options{LeftDelim: "[[", RightDelim: "]]"}); err == nil {
+		t.Error("New accepted custom delimiters for pongo2")
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := map[string]any{"Name": "OrderItem", "Enabled": true, "Port": 8080}
+	tests := []struct {
+		engine  string
+		opts    Options
+		content string
+		want    string
+	}{
+		{DefaultEngine, Options{}, "{{ .Name }}", "OrderItem"},
+		{DefaultEngine, Options{}, "{{ snakeCase .Name }} {{ camelCase .Name }} {{ plural .Name }}", "order_item orderItem OrderItems"},
+		{DefaultEngine, Options{}, "{{ add .Port 1 }} {{ div 7 2 }} {{ mul 1.5 2 }}", "8081 3 3"},
+		{DefaultEngine, Options{}, "{{ semverBump \"minor\" \"v1.2.3-rc.1\" }}", "v1.3.0"},
+		{DefaultEngine, Options{LeftDelim: "[[", RightDelim: "]]"}, "{{ .Values.image }} [[ .Name ]]", "{{ .Values.image }} OrderItem"},
+		{DefaultEngine, Options{}, "a\n  {{ if .Enabled }}\nb\n  {{ end }}\nc\n", "a\n  \nb\n  \nc\n"},
+		{DefaultEngine, Options{TrimBlocks: true, LStripBlocks: true}, "a\n  {{ if .Enabled }}\nb\n  {{ end }}\nc\n", "a\nb\nc\n"},
+		{DefaultEngine, Options{TrimBlocks: true, LeftDelim: "[[", RightDelim: "]]"}, "[[ if .Enabled ]]\nb\n[[ end ]]\n", "b\n"},
+		{"pongo2", Options{}, "{{ Name }}{% if Enabled %} on {{ Port }}{% endif %}", "OrderItem on 8080"},
+		{"pongo2", Options{TrimBlocks: true}, "{% if Enabled %}\nb\n{% endif %}\n", "b\n"},
+	}
+	for _, tt := range tests {
+		engine, err := New(tt.engine, BuiltinFuncs(), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := engine.Render("test", tt.content, data)
+		if err != nil {
+			t.Errorf("%s: Render(%q): %v", tt.engine, tt.content, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Render(%q) = %q, want %q", tt.engine, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	engine, err := New("", BuiltinFuncs(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"{{ .Name", "{{ div 1 0 }}", "{{ semverBump \"huge\" \"1.0.0\" }}", "{{ undefined }}"} {
+		if out, err := engine.Render("test", content, nil); err == nil {
+			t.Errorf("Render(%q) = %q, want an error", content, out)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		in, snake, camel, plural string
+	}{
+		{"OrderItem", "order_item", "orderItem", "OrderItems"},
+		{"order_item", "order_item", "orderItem", "order_items"},
+		{"HTTPServer", "http_server", "httpServer", "HTTPServers"},
+		{"category", "category", "category", "categories"},
+		{"day", "day", "day", "days"},
+		{"box", "box", "box", "boxes"},
+		{"order-état", "order_état", "orderÉtat", "order-états"},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := snakeCase(tt.in); got != tt.snake {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := camelCase(tt.in); got != tt.camel {
+			t.Errorf("camelCase(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := plural(tt.in); got != tt.plural {
+			t.Errorf("plural(%q) = %q, want %q", tt.in, got, tt.plural)
+		}
+	}
+}
+
+func TestSemver(t *testing.T) {
+	tests := []struct {
+		part, in, want string
+	}{
+		{"major", "1.2.3", "v2.0.0"},
+		{"minor", "v1.2.3+build", "v1.3.0"},
+		{"patch", "v1.2.3-rc.1", "v1.2.4"},
+		{"patch", "v1.2", "v1.2.1"},
+	}
+	for _, tt := range tests {
+		if got, err := semverBump(tt.part, tt.in); err != nil || got != tt.want {
+			t.Errorf("semverBump(%q, %q) = %q, %v, want %q", tt.part, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseSemver("latest"); err == nil {
+		t.Error("parseSemver accepted latest")
+	}
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "v1.0.0", 0},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+	} {
+		if got, err := semverCompare(tt.a, tt.b); err != nil || got != tt.want {
+			t.Errorf("semverCompare(%q, %q) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestIncludeFile(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "template")
+	if err := os.MkdirAll(filepath.Join(root, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(root, "data", "regions.yaml"): "- eu\n- us\n",
+		filepath.Join(root, "data", "ports.json"):   `{"http": 8080}`,
+		filepath.Join(dir, "secret"):                "stolen",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine, err := New("", Funcs(root), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := engine.Render("test", `{{ range readYAML "data/regions.yaml" }}{{ . }} {{ end }}{{ (readJSON "data/ports.json").http }}`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "eu us 8080"; string(out) != want {
+		t.Errorf("Render = %q, want %q", out, want)
+	}
+
+	for _, name := range []string{"../secret", "data/../../secret", filepath.ToSlash(filepath.Join(dir, "secret"))} {
+		if out, err := engine.Render("test", `{{ includeFile "`+name+`" }}`, nil); err == nil {
+			t.Errorf("includeFile %s = %q, want an error", name, out)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	value, err := Generate("Secret", "randAlphaNum 32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) != 32 {
+		t.Errorf("Generate(randAlphaNum 32) = %q, want 32 characters", value)
+	}
+	if _, err := Generate("Secret", "unknownFunc"); err == nil {
+		t.Error("Generate accepted an unknown function")
+	}
+}
+
+// largeTemplate returns a synthetic template of n blocks for engine, each
+// using a variable, a function and a condition, as generated Go sources do.
+func largeTemplate(engine string, n int) string {
+	block := "func Handler() string {\n\treturn \"{{ .Name }}\" // {{ upper .Name }}\n}\n{{ if .Enabled }}// enabled\n{{ end }}\n"
+	if engine == "pongo2" {
+		block = "func Handler() string {\n\treturn \"{{ Name }}\" // {{ upper(Name) }}\n}\n{% if Enabled %}// enabled\n{% endif %}\n"
+	}
+	return strings.Repeat(block, n)
+}
+
+func benchmarkEngine(b *testing.B, name string, opts Options) {
+	engine, err := New(name, BuiltinFuncs(), opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	content := largeTemplate(name, 2000)
+	data := map[string]any{"Name": "service", "Enabled": true}
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := engine.Render("large.go", content, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGoTemplate(b *testing.B) {
+	benchmarkEngine(b, DefaultEngine, Options{})
+}
+
+func BenchmarkGoTemplateTrimBlocks(b *testing.B) {
+	benchmarkEngine(b, DefaultEngine, Options{TrimBlocks: true, LStripBlocks: true})
+}
+
+func BenchmarkPongo2(b *testing.B) {
+	benchmarkEngine(b, "pongo2", Options{})
+}
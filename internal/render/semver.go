@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+}
+
+// String formats the version with a leading "v".
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += v.Prerelease
+	}
+	if v.Build != "" {
+		s += v.Build
+	}
+	return s
+}
+
+// parseSemver parses a semantic version, with or without the leading "v".
+func parseSemver(s string) (Version, error) {
+	v := s
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return Version{}, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(semver.Canonical(v), "v"), ".", 3)
+	patch, _, _ := strings.Cut(strings.SplitN(parts[2], "+", 2)[0], "-")
+	version := Version{
+		Prerelease: semver.Prerelease(v),
+		Build:      semver.Build(v),
+	}
+	version.Major, _ = strconv.Atoi(parts[0])
+	version.Minor, _ = strconv.Atoi(parts[1])
+	version.Patch, _ = strconv.Atoi(patch)
+	return version, nil
+}
+
+// semverBump increments the major, minor or patch part of s, resetting the
+// parts after it and dropping any prerelease and build metadata.
+func semverBump(part, s string) (string, error) {
+	v, err := parseSemver(s)
+	if err != nil {
+		return "", err
+	}
+	switch part {
+	case "major":
+		v = Version{Major: v.Major + 1}
+	case "minor":
+		v = Version{Major: v.Major, Minor: v.Minor + 1}
+	case "patch":
+		v = Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	default:
+		return "", fmt.Errorf("semverBump: unknown part %q, must be major, minor or patch", part)
+	}
+	return v.String(), nil
+}
+
+// semverCompare returns -1, 0 or 1 as a is lower than, equal to or higher than b.
+func semverCompare(a, b string) (int, error) {
+	va, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return semver.Compare(va.String(), vb.String()), nil
+}
@@ -0,0 +1,29 @@
+package render
+
+import (
+	"time"
+)
+
+// now returns the current time, e.g. {{ now.Year }} or {{ now.Format "2006-01-02" }}.
+func now() time.Time {
+	return time.Now()
+}
+
+// dateFormat formats t with the Go reference time layout.
+func dateFormat(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// duration parses a duration such as "1h30m".
+func duration(s string) (time.Duration, error) {
+	return time.ParseDuration(s)
+}
+
+// dateAdd returns t moved by the duration d, such as "720h" or "-1h".
+func dateAdd(d string, t time.Time) (time.Time, error) {
+	parsed, err := time.ParseDuration(d)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(parsed), nil
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Options control how engines treat the whitespace around block tags, such
+// as {{ if }} or {% for %}, so conditional blocks do not leave blank lines,
+// and the delimiters of actions.
+type Options struct {
+	// TrimBlocks removes the first newline after a block tag.
+	TrimBlocks bool
+	// LStripBlocks removes the spaces and tabs before a block tag starting
+	// a line.
+	LStripBlocks bool
+	// LeftDelim and RightDelim replace {{ and }} as the action delimiters
+	// of text/template, for templates generating Go-templated files such as
+	// Helm charts. Empty means the default.
+	LeftDelim  string
+	RightDelim string
+	// Root is the directory of the template. The include, import and
+	// extends tags of pongo2 only load files within it, and none if it is
+	// empty.
+	Root string
+}
+
+// delims returns the action delimiters.
+func (o Options) delims() (left, right string) {
+	left, right = o.LeftDelim, o.RightDelim
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+	return left, right
+}
+
+// blockActions matches the start of the text/template actions that produce
+// no output: control structures, definitions and comments.
+const blockActions = `-?\s*(?:(?:if|else|end|range|with|define|block|break|continue)\b|/\*)`
+
+// whitespace rewrites content for text/template as options ask.
+type whitespace struct {
+	lstripActions *regexp.Regexp
+	// trimActions matches the start of block actions, which end at the
+	// first right delimiter after it.
+	trimActions *regexp.Regexp
+	right       string
+}
+
+func newWhitespace(o Options) whitespace {
+	left, right := o.delims()
+	blockAction := regexp.QuoteMeta(left) + blockActions
+	var w whitespace
+	if o.LStripBlocks {
+		w.lstripActions = regexp.MustCompile(`(?m)^[ \t]+(` + blockAction + `)`)
+	}
+	if o.TrimBlocks {
+		w.trimActions = regexp.MustCompile(blockAction)
+		w.right = right
+	}
+	return w
+}
+
+// apply rewrites content for text/template.
+func (w whitespace) apply(content string) string {
+	if w.lstripActions != nil {
+		content = w.lstripActions.ReplaceAllString(content, "$1")
+	}
+	if w.trimActions != nil {
+		content = w.trim(content)
+	}
+	return content
+}
+
+// trim removes the newline right after each block action, leaving those
+// after other actions or text that follow a block action on its line.
+func (w whitespace) trim(content string) string {
+	var b strings.Builder
+	for {
+		loc := w.trimActions.FindStringIndex(content)
+		if loc == nil {
+			break
+		}
+		end := strings.Index(content[loc[1]:], w.right)
+		if end < 0 {
+			break
+		}
+		end += loc[1] + len(w.right)
+		b.WriteString(content[:end])
+		content = content[end:]
+		if strings.HasPrefix(content, "\n") {
+			content = content[1:]
+		} else if strings.HasPrefix(content, "\r\n") {
+			content = content[2:]
+		}
+	}
+	b.WriteString(content)
+	return b.String()
+}
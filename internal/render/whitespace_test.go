@@ -0,0 +1,32 @@
+package render
+
+import "testing"
+
+func TestWhitespace(t *testing.T) {
+	trim := Options{TrimBlocks: true}
+	lstrip := Options{LStripBlocks: true}
+	tests := []struct {
+		opts     Options
+		in, want string
+	}{
+		{trim, "{{ if .A }}\nb\n{{ end }}\nc", "{{ if .A }}b\n{{ end }}c"},
+		{trim, "{{ if .A }}\r\nb", "{{ if .A }}b"},
+		{trim, "{{ if .A }}{{ .N }}\nnext", "{{ if .A }}{{ .N }}\nnext"},
+		{trim, "{{ if .A }} text\nnext", "{{ if .A }} text\nnext"},
+		{trim, "{{ .N }}\n{{ range .L }}\n", "{{ .N }}\n{{ range .L }}"},
+		{trim, "{{- else -}}\n", "{{- else -}}"},
+		{trim, "{{/* a\ncomment */}}\nb", "{{/* a\ncomment */}}b"},
+		{trim, "{{ iffy }}\n{{ ending }}\n", "{{ iffy }}\n{{ ending }}\n"},
+		{trim, "{{ if .A", "{{ if .A"},
+		{Options{TrimBlocks: true, LeftDelim: "[[", RightDelim: "]]"}, "[[ if .A ]][[ .N ]]\n[[ end ]]\n", "[[ if .A ]][[ .N ]]\n[[ end ]]"},
+		{lstrip, "a\n  {{ if .A }}\n\t{{ end }}\n", "a\n{{ if .A }}\n{{ end }}\n"},
+		{lstrip, "  {{ .N }}\n", "  {{ .N }}\n"},
+		{lstrip, "a {{ if .A }}", "a {{ if .A }}"},
+		{Options{TrimBlocks: true, LStripBlocks: true}, "a\n  {{ if .A }}\n  b\n  {{ end }}\n", "a\n{{ if .A }}  b\n{{ end }}"},
+	}
+	for _, tt := range tests {
+		if got := newWhitespace(tt.opts).apply(tt.in); got != tt.want {
+			t.Errorf("apply(%q) with %+v = %q, want %q", tt.in, tt.opts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package safepath validates filesystem paths before gonew writes to them.
+package safepath
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Resolve returns the absolute, symlink-free form of path. Path need not
+// exist: symlinks are resolved for its deepest existing ancestor and the
+// remaining elements are appended unchanged.
+func Resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var rest []string
+	for p := abs; ; p = filepath.Dir(p) {
+		resolved, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			for i := len(rest) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, rest[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return abs, nil
+		}
+		rest = append(rest, filepath.Base(p))
+	}
+}
+
+// Within reports whether target is base or lies inside it.
+// Both paths must already be resolved.
+func Within(base, target string) bool {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel))
+}
+
+// CheckRegular reports an error unless path is a regular file resolving
+// within root, which must already be resolved. Symlinks, which could point
+// at files of the host such as SSH keys, devices and other special files
+// are refused.
+func CheckRegular(root, path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s: not a regular file", path)
+	}
+	resolved, err := Resolve(path)
+	if err != nil {
+		return err
+	}
+	if !Within(root, resolved) {
+		return fmt.Errorf("%s: resolves outside of %s", path, root)
+	}
+	return nil
+}
+
+// IsRoot reports whether path is a filesystem root, e.g. "/" or `C:\`.
+func IsRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+// CheckRel reports an error if rel, a path taken from a template source,
+// could escape the directory it is joined to: it must be relative, must not
+// contain ".." elements and must not carry a volume name such as "C:".
+func CheckRel(rel string) error {
+	if rel == "" {
+		return fmt.Errorf("empty path")
+	}
+	if filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") || strings.HasPrefix(rel, `\`) {
+		return fmt.Errorf("%s: absolute path", rel)
+	}
+	if filepath.VolumeName(rel) != "" || hasDriveLetter(rel) {
+		return fmt.Errorf("%s: path has a volume name", rel)
+	}
+	for _, elem := range strings.FieldsFunc(rel, isSeparator) {
+		if elem == ".." {
+			return fmt.Errorf("%s: path escapes the target directory", rel)
+		}
+	}
+	return nil
+}
+
+// hasDriveLetter reports whether path starts with a Windows drive letter,
+// which filepath.VolumeName only detects when running on Windows.
+func hasDriveLetter(path string) bool {
+	if len(path) < 2 || path[1] != ':' {
+		return false
+	}
+	c := path[0]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// Segment returns s, a value meant to be part of a single path element such
+// as an answer used in a file name, normalized to Unicode NFC, without
+// control characters, and with path separators replaced by dashes, so that
+// "foo/bar" cannot create nested directories.
+func Segment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\':
+			return '-'
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, norm.NFC.String(s))
+}
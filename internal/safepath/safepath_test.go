@@ -0,0 +1,126 @@
+package safepath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckRel(t *testing.T) {
+	tests := []struct {
+		rel string
+		ok  bool
+	}{
+		{"main.go", true},
+		{"cmd/root.go", true},
+		{"..foo/bar", true},
+		{"", false},
+		{"..", false},
+		{"../main.go", false},
+		{"cmd/../../main.go", false},
+		{`cmd\..\..\main.go`, false},
+		{"/etc/passwd", false},
+		{`\windows\system32`, false},
+		{"C:main.go", false},
+		{`C:\main.go`, false},
+	}
+	for _, tt := range tests {
+		if err := CheckRel(tt.rel); (err == nil) != tt.ok {
+			t.Errorf("CheckRel(%q) = %v, want ok %v", tt.rel, err, tt.ok)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		base, target string
+		want         bool
+	}{
+		{"/a", "/a", true},
+		{"/a", "/a/b", true},
+		{"/a", "/a/..b", true},
+		{"/a", "/ab", false},
+		{"/a", "/", false},
+		{"/a/b", "/a", false},
+	}
+	for _, tt := range tests {
+		if got := Within(filepath.FromSlash(tt.base), filepath.FromSlash(tt.target)); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.base, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "real"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Resolve(filepath.Join(dir, "link", "missing", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "real", "missing", "file"); got != want {
+		t.Errorf("Resolve = %s, want %s", got, want)
+	}
+}
+
+func TestCheckRegular(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret")
+	for _, path := range []string{filepath.Join(root, "file"), outside} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "outside")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("file", filepath.Join(root, "inside")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"file", true},
+		{"outside", false},
+		{"inside", false},
+		{".", false},
+		{"missing", false},
+	}
+	for _, tt := range tests {
+		if err := CheckRegular(root, filepath.Join(root, tt.name)); (err == nil) != tt.ok {
+			t.Errorf("CheckRegular(%s) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+	if err := CheckRegular(root, outside); err == nil {
+		t.Errorf("CheckRegular of a file outside of the root succeeded")
+	}
+}
+
+func TestSegment(t *testing.T) {
+	tests := map[string]string{
+		"service":     "service",
+		"foo/bar":     "foo-bar",
+		`foo\bar`:     "foo-bar",
+		"../etc":      "..-etc",
+		"a\x00b\nc":   "abc",
+		"e\u0301tude": "\u00e9tude",
+	}
+	for in, want := range tests {
+		if got := Segment(in); got != want {
+			t.Errorf("Segment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package sandbox confines the files gonew writes to a single output root
+// and records every write in an audit log.
+package sandbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/betterde/gonew/internal/safepath"
+)
+
+// Sandbox performs filesystem operations relative to its root, refusing any
+// that would resolve outside of it, including through symlinks.
+type Sandbox struct {
+	root  string
+	mu    sync.Mutex
+	audit io.Writer
+}
+
+// record is an audit log entry, written as a JSON line.
+type record struct {
+	Time   time.Time `json:"time"`
+	Op     string    `json:"op"`
+	Path   string    `json:"path"`
+	Size   int       `json:"size,omitempty"`
+	SHA256 string    `json:"sha256,omitempty"`
+}
+
+// New returns a sandbox rooted at root, which must exist. Audit may be nil.
+func New(root string, audit io.Writer) (*Sandbox, error) {
+	resolved, err := safepath.Resolve(root)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	return &Sandbox{root: resolved, audit: audit}, nil
+}
+
+// Root returns the resolved root directory.
+func (s *Sandbox) Root() string {
+	return s.root
+}
+
+// path validates rel and returns its absolute path inside the root.
+func (s *Sandbox) path(rel string) (string, error) {
+	if err := safepath.CheckRel(rel); err != nil {
+		return "", err
+	}
+	target := filepath.Join(s.root, filepath.Clean(rel))
+	resolved, err := safepath.Resolve(target)
+	if err != nil {
+		return "", err
+	}
+	if !safepath.Within(s.root, resolved) {
+		return "", fmt.Errorf("%s resolves outside of %s", rel, s.root)
+	}
+	return target, nil
+}
+
+// ReadFile reads the file at rel.
+func (s *Sandbox) ReadFile(rel string) ([]byte, error) {
+	target, err := s.path(rel)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(target)
+}
+
+// WriteFile writes data to the file at rel, creating its parent directories.
+func (s *Sandbox) WriteFile(rel string, data []byte, perm os.FileMode) error {
+	target, err := s.path(rel)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return err
+	}
+
+	// Resolve again now that the parents exist, in case one of them
+	// was swapped for a symlink in the meantime.
+	if _, err := s.path(rel); err != nil {
+		return err
+	}
+	if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%s: refusing to write through a symlink", rel)
+	}
+
+	if err := os.WriteFile(target, data, perm); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	s.log(record{Op: "write", Path: filepath.ToSlash(rel), Size: len(data), SHA256: hex.EncodeToString(sum[:])})
+	return nil
+}
+
+// Remove removes the file at rel.
+func (s *Sandbox) Remove(rel string) error {
+	target, err := s.path(rel)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil {
+		return err
+	}
+	s.log(record{Op: "remove", Path: filepath.ToSlash(rel)})
+	return nil
+}
+
+// Chtimes sets the access and modification times of the file or directory at rel.
+func (s *Sandbox) Chtimes(rel string, mtime time.Time) error {
+	target, err := s.path(rel)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%s: refusing to change times through a symlink", rel)
+	}
+	return os.Chtimes(target, mtime, mtime)
+}
+
+func (s *Sandbox) log(r record) {
+	if s.audit == nil {
+		return
+	}
+	r.Time = time.Now().UTC()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audit.Write(append(data, '\n'))
+}
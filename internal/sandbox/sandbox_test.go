@@ -0,0 +1,115 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSandboxEscapes(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	outside := filepath.Join(parent, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret := filepath.Join(outside, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "linkdir")); err != nil {
+		t.Skip(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "linkfile")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	box, err := New(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range []string{
+		"../outside/secret",
+		"a/../../outside/secret",
+		secret,
+		"linkdir/secret",
+		"linkdir/new",
+		"linkfile",
+		"dangling",
+	} {
+		if err := box.WriteFile(rel, []byte("x"), 0o644); err == nil {
+			t.Errorf("WriteFile(%q) succeeded", rel)
+		}
+		if _, err := box.ReadFile(rel); err == nil {
+			t.Errorf("ReadFile(%q) succeeded", rel)
+		}
+		if err := box.Chtimes(rel, time.Unix(0, 0)); err == nil {
+			t.Errorf("Chtimes(%q) succeeded", rel)
+		}
+		// Removing a dangling symlink removes the link itself, in the root.
+		if err := box.Remove(rel); err == nil && rel != "dangling" {
+			t.Errorf("Remove(%q) succeeded", rel)
+		}
+	}
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file outside the sandbox = %q, %v, want it untouched", data, err)
+	}
+	for _, name := range []string{"new", "missing"} {
+		if _, err := os.Stat(filepath.Join(outside, name)); !os.IsNotExist(err) {
+			t.Errorf("%s created outside the sandbox through a symlink: %v", name, err)
+		}
+	}
+}
+
+func TestSandboxAudit(t *testing.T) {
+	var audit bytes.Buffer
+	box, err := New(t.TempDir(), &audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := box.WriteFile("cmd/main.go", []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := box.ReadFile("cmd/main.go"); err != nil || string(data) != "package main\n" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if err := box.Remove("cmd/main.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ops []string
+	decoder := json.NewDecoder(&audit)
+	for decoder.More() {
+		var r record
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Path != "cmd/main.go" {
+			t.Errorf("audit record for %q, want cmd/main.go", r.Path)
+		}
+		ops = append(ops, r.Op)
+	}
+	if len(ops) != 2 || ops[0] != "write" || ops[1] != "remove" {
+		t.Errorf("audit ops = %v, want write and remove", ops)
+	}
+}
+
+func TestNewRequiresDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, root := range []string{file, filepath.Join(t.TempDir(), "missing")} {
+		if _, err := New(root, nil); err == nil {
+			t.Errorf("New(%s) succeeded", root)
+		}
+	}
+}
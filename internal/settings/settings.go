@@ -0,0 +1,70 @@
+// Package settings reads the user's gonew configuration file, holding the
+// defaults of settings otherwise given by flags or environment variables.
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/betterde/gonew/internal/paths"
+	"github.com/betterde/gonew/internal/project"
+	"github.com/betterde/gonew/internal/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file in the config directory.
+const FileName = "config.yaml"
+
+// Settings is the user's configuration.
+type Settings struct {
+	// Index is the template index used when neither --index nor
+	// GONEW_INDEX names one, a file or an http(s) URL. A relative path is
+	// relative to the configuration file.
+	Index string `yaml:"index"`
+	// Destination is the organization's policy on the module paths of
+	// generated projects, applied on top of the policies of templates.
+	Destination *project.Destination `yaml:"destination"`
+	// Analytics, off unless set, counts every project generated from a
+	// template of the index in the index, by template and version only,
+	// so that popular templates can be told apart.
+	Analytics bool `yaml:"analytics"`
+	// RemoteEnv lists the environment variables, such as CATALOG_TOKEN,
+	// the headers of validate_remote declarations may refer to. Templates
+	// cannot send any other variable to their endpoints.
+	RemoteEnv []string `yaml:"remote_env"`
+}
+
+// Path returns the location of the configuration file.
+func Path() (string, error) {
+	dir, err := paths.Config()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the configuration file at filename. A missing file is an empty
+// configuration.
+func Load(filename string) (*Settings, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if s.Destination != nil {
+		if err := s.Destination.Check(); err != nil {
+			return nil, fmt.Errorf("%s: destination: %v", filename, err)
+		}
+	}
+	if s.Index != "" && !filepath.IsAbs(s.Index) && !registry.IsURL(s.Index) {
+		s.Index = filepath.Join(filepath.Dir(filename), s.Index)
+	}
+	return s, nil
+}
@@ -0,0 +1,37 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadIndex(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"templates.yaml":                     filepath.Join(dir, "templates.yaml"),
+		"/srv/templates.yaml":                "/srv/templates.yaml",
+		"https://example.com/templates.yaml": "https://example.com/templates.yaml",
+		"http://localhost:8080/index.json":   "http://localhost:8080/index.json",
+	}
+	for index, want := range tests {
+		filename := filepath.Join(dir, FileName)
+		if err := os.WriteFile(filename, []byte("index: "+index+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		s, err := Load(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Index != want {
+			t.Errorf("index %s loaded as %s, want %s", index, s.Index, want)
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil || s.Index != "" {
+		t.Errorf("Load of a missing file = %+v, %v, want empty settings", s, err)
+	}
+}
@@ -0,0 +1,29 @@
+// Package terminal detects whether gonew runs in a terminal able to show
+// interactive prompts, which redraw lines and read keys in raw mode.
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Interactive reports whether standard input and output are a terminal
+// supporting interactive prompts, and otherwise why not: a dumb terminal,
+// input or output that is not a terminal, as in many IDE consoles, or a
+// legacy Windows console without escape sequences.
+func Interactive() (bool, string) {
+	if term := os.Getenv("TERM"); term == "dumb" {
+		return false, "TERM is dumb"
+	}
+	// Emacs shell buffers are not terminals, unlike its terminal emulators.
+	if inside := os.Getenv("INSIDE_EMACS"); strings.Contains(inside, "comint") {
+		return false, "running in an Emacs shell buffer"
+	}
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		if ok, reason := capable(f); !ok {
+			return false, fmt.Sprintf("%s %s", f.Name(), reason)
+		}
+	}
+	return true, ""
+}
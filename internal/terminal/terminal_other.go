@@ -0,0 +1,14 @@
+//go:build !windows
+
+package terminal
+
+import "os"
+
+// capable reports whether f is a terminal rather than a pipe or a file.
+func capable(f *os.File) (bool, string) {
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false, "is not a terminal"
+	}
+	return true, ""
+}
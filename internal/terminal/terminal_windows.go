@@ -0,0 +1,26 @@
+//go:build windows
+
+package terminal
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// capable reports whether f is a console, and for output one that
+// interprets escape sequences, enabling them if needed.
+func capable(f *os.File) (bool, string) {
+	handle := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false, "is not a console"
+	}
+	if f != os.Stdout || mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true, ""
+	}
+	if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		return false, "is a legacy console without escape sequences"
+	}
+	return true, ""
+}
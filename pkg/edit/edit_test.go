@@ -0,0 +1,109 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEdit(t *testing.T) {
+	b := NewBuffer([]byte("0123456789"))
+	b.Insert(8, ",7½,")
+	b.Replace(9, 10, "the-end")
+	b.Insert(10, "!")
+	b.Insert(4, "3.14,")
+	b.Insert(4, "π,")
+	b.Insert(4, "3.15,")
+	b.Replace(3, 4, "three,")
+	want := "012three,3.14,π,3.15,4567,7½,8the-end!"
+
+	s := b.String()
+	if s != want {
+		t.Errorf("b.String() = %q, want %q", s, want)
+	}
+	sb := b.Bytes()
+	if string(sb) != want {
+		t.Errorf("b.Bytes() = %q, want %q", sb, want)
+	}
+}
+
+func TestEditPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(b *Buffer)
+	}{
+		{"insert before start", func(b *Buffer) { b.Insert(-1, "x") }},
+		{"insert after end", func(b *Buffer) { b.Insert(4, "x") }},
+		{"delete backwards", func(b *Buffer) { b.Delete(2, 1) }},
+		{"replace after end", func(b *Buffer) { b.Replace(1, 4, "x") }},
+		{"overlapping edits", func(b *Buffer) { b.Replace(0, 2, "x"); b.Delete(1, 3); b.Bytes() }},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", tt.name)
+				}
+			}()
+			tt.fn(NewBuffer([]byte("abc")))
+		}()
+	}
+}
+
+// FuzzBuffer queues edits decoded from ops, three bytes each giving the
+// start, the length of the text replaced and the length of the new text, in
+// reverse order, and checks the result against applying them one at a time
+// from the end.
+func FuzzBuffer(f *testing.F) {
+	f.Add([]byte("0123456789"), []byte{8, 0, 3, 9, 1, 7, 10, 0, 1, 4, 0, 5})
+	f.Add([]byte("package main\n\nimport \"example.com/old\"\n"), []byte{8, 4, 3, 22, 15, 15, 22, 0, 4})
+	f.Add([]byte(""), []byte{0, 0, 1, 0, 0, 2})
+	f.Add([]byte("abc"), []byte{0, 3, 0, 3, 0, 1})
+	f.Fuzz(func(t *testing.T, old, ops []byte) {
+		type op struct {
+			start, end int
+			new        string
+		}
+		// Decode edits that do not overlap, ordered by start. No two
+		// insertions share a position, as their order would then depend
+		// on the order they are queued in.
+		var edits []op
+		pos := 0
+		for i := 0; i+2 < len(ops) && pos <= len(old); i += 3 {
+			start := pos + int(ops[i])%(len(old)-pos+1)
+			end := start + int(ops[i+1])%(len(old)-start+1)
+			new := strings.Repeat(string(rune('a'+len(edits)%26)), int(ops[i+2])%8)
+			edits = append(edits, op{start, end, new})
+			pos = end
+			if start == end {
+				pos++
+			}
+		}
+
+		b := NewBuffer(old)
+		// Queue the edits out of order, the buffer sorts them.
+		for i := len(edits) - 1; i >= 0; i-- {
+			e := edits[i]
+			switch {
+			case e.start == e.end:
+				b.Insert(e.start, e.new)
+			case e.new == "":
+				b.Delete(e.start, e.end)
+			default:
+				b.Replace(e.start, e.end, e.new)
+			}
+		}
+
+		want := string(old)
+		for i := len(edits) - 1; i >= 0; i-- {
+			e := edits[i]
+			want = want[:e.start] + e.new + want[e.end:]
+		}
+		if got := b.String(); got != want {
+			t.Errorf("edits %v of %q = %q, want %q", edits, old, got, want)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+// Package progress describes the events gonew reports while generating a
+// project, so that programs driving it, such as GUIs and servers, can show
+// live progress instead of waiting for it to finish. gonew init --events
+// writes them as JSON lines, which JSONLines and Decode read and write:
+//
+//	events := make(chan progress.Event)
+//	go func() {
+//		progress.Decode(stdout, progress.Chan(events))
+//		close(events)
+//	}()
+//
+// Programs running gonew in process pass a Handler in the context of the
+// command instead:
+//
+//	ctx := progress.NewContext(ctx, func(e progress.Event) { ... })
+//	err := cmd.ExecuteContext(ctx, "init", "example.com/template", "example.com/app")
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of an event.
+type Kind string
+
+const (
+	// KindPhase starts a phase of the generation, named by Phase.
+	KindPhase Kind = "phase"
+	// KindFile reports a file written, the Index-th of Total.
+	KindFile Kind = "file"
+	// KindPrompt reports that the answer to Variable is asked for.
+	KindPrompt Kind = "prompt"
+	// KindOutput carries a line of output of a command run for the
+	// template, such as a formatter, in Text.
+	KindOutput Kind = "output"
+	// KindWarning reports a problem that does not stop the generation,
+	// identified by Code and explained in Text, about the file at Path if
+	// any.
+	KindWarning Kind = "warning"
+)
+
+// Phases of the generation, in order.
+const (
+	PhaseDownload = "download"
+	PhasePrompt   = "prompt"
+	PhasePlan     = "plan"
+	PhaseWrite    = "write"
+	PhaseFormat   = "format"
+	PhaseDone     = "done"
+)
+
+// Event is a step of the generation.
+type Event struct {
+	Kind     Kind      `json:"kind"`
+	Time     time.Time `json:"time"`
+	Phase    string    `json:"phase,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Index    int       `json:"index,omitempty"`
+	Total    int       `json:"total,omitempty"`
+	Variable string    `json:"variable,omitempty"`
+	Text     string    `json:"text,omitempty"`
+	Code     string    `json:"code,omitempty"`
+}
+
+// Handler receives events as they happen.
+type Handler func(Event)
+
+// Discard is a Handler ignoring every event.
+func Discard(Event) {}
+
+// Chan returns a Handler sending the events on ch.
+func Chan(ch chan<- Event) Handler {
+	return func(e Event) {
+		ch <- e
+	}
+}
+
+// Tee returns a Handler passing every event to each of handlers in turn.
+func Tee(handlers ...Handler) Handler {
+	return func(e Event) {
+		for _, h := range handlers {
+			h(e)
+		}
+	}
+}
+
+type handlerKey struct{}
+
+// NewContext returns a copy of ctx carrying h, which the generation run with
+// it reports its events to.
+func NewContext(ctx context.Context, h Handler) context.Context {
+	return context.WithValue(ctx, handlerKey{}, h)
+}
+
+// FromContext returns the Handler carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) Handler {
+	h, _ := ctx.Value(handlerKey{}).(Handler)
+	return h
+}
+
+// JSONLines returns a Handler writing the events to w, one JSON object per
+// line. It is safe for concurrent use.
+func JSONLines(w io.Writer) Handler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(e)
+	}
+}
+
+// Decode reads JSON lines events from r, as written by JSONLines, and passes
+// them to h until r ends.
+func Decode(r io.Reader, h Handler) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var e Event
+		if err := decoder.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		h(e)
+	}
+}
@@ -0,0 +1,46 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJSONLines(t *testing.T) {
+	events := []Event{
+		{Kind: KindPhase, Time: time.Unix(1, 0).UTC(), Phase: PhaseWrite},
+		{Kind: KindFile, Time: time.Unix(2, 0).UTC(), Path: "main.go", Index: 1, Total: 2},
+		{Kind: KindWarning, Time: time.Unix(3, 0).UTC(), Code: "W001", Text: "unused"},
+	}
+	var buf bytes.Buffer
+	h := JSONLines(&buf)
+	for _, e := range events {
+		h(e)
+	}
+
+	var got []Event
+	if err := Decode(&buf, func(e Event) { got = append(got, e) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("decoded %d events, want %d", len(got), len(events))
+	}
+	for i := range events {
+		if got[i] != events[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], events[i])
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if h := FromContext(context.Background()); h != nil {
+		t.Error("FromContext of a context without a handler is not nil")
+	}
+	var n int
+	ctx := NewContext(context.Background(), Tee(func(Event) { n++ }, func(Event) { n += 10 }))
+	FromContext(ctx)(Event{Kind: KindPhase})
+	if n != 11 {
+		t.Errorf("handlers saw %d, want each called once", n)
+	}
+}
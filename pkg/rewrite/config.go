@@ -0,0 +1,146 @@
+package rewrite
+
+import (
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Handler rewrites the module path in the content of a config file.
+type Handler func(data []byte, opts Options) ([]byte, error)
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{
+		// golangci-lint: goimports local-prefixes, gci sections.
+		".golangci.yml":  Paths,
+		".golangci.yaml": Paths,
+		".golangci.toml": Paths,
+		".golangci.json": Paths,
+		// mockery: packages keyed by import path.
+		".mockery.yaml": Paths,
+		".mockery.yml":  Paths,
+		// sqlc: go_type and import overrides.
+		"sqlc.yaml": Paths,
+		"sqlc.yml":  Paths,
+		"sqlc.json": Paths,
+		// Build files spelling out packages in -ldflags, image names or go run.
+		".goreleaser.yml":  Paths,
+		".goreleaser.yaml": Paths,
+		"Dockerfile":       Paths,
+		"Makefile":         Paths,
+		// GitHub Actions workflows running or building packages.
+		".github/workflows/*.yml":  Paths,
+		".github/workflows/*.yaml": Paths,
+	}
+)
+
+// Register makes h rewrite the config files whose base name matches
+// pattern, in path.Match syntax, or with a slash-separated pattern such as
+// ".github/workflows/*.yml" whose last elements match it. A later
+// registration for the same pattern replaces the earlier one.
+func Register(pattern string, h Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[pattern] = h
+}
+
+// Lookup returns the handler for the config file at name, preferring an
+// exact base name over a pattern.
+func Lookup(name string) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	name = strings.ReplaceAll(name, "\\", "/")
+	base := path.Base(name)
+	if h, ok := handlers[base]; ok {
+		return h, true
+	}
+	elems := strings.Split(name, "/")
+	patterns := make([]string, 0, len(handlers))
+	for pattern := range handlers {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		target := base
+		if n := strings.Count(pattern, "/") + 1; n > 1 {
+			if n > len(elems) {
+				continue
+			}
+			target = strings.Join(elems[len(elems)-n:], "/")
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return handlers[pattern], true
+		}
+	}
+	return nil, false
+}
+
+// Config rewrites the config file at name with its registered handler. It
+// returns data unchanged if no handler knows the file.
+func Config(name string, data []byte, opts Options) ([]byte, error) {
+	h, ok := Lookup(name)
+	if !ok {
+		return data, nil
+	}
+	return h(data, opts)
+}
+
+// Paths is a Handler replacing every import path under From in data, as a
+// whole path rather than a prefix of a longer one, with its new path. With
+// an empty From there is no path to replace and data is returned unchanged.
+func Paths(data []byte, opts Options) ([]byte, error) {
+	if opts.From == "" {
+		return data, nil
+	}
+	text := string(data)
+	var b strings.Builder
+	for {
+		i := strings.Index(text, opts.From)
+		if i < 0 {
+			break
+		}
+		end := i + len(opts.From)
+		for end < len(text) && isPathChar(text[end]) {
+			end++
+		}
+		// A path ends a sentence or a directory name rather than
+		// continuing with a dot or slash.
+		for end > i+len(opts.From) && (text[end-1] == '.' || text[end-1] == '/') {
+			end--
+		}
+		pkg, qualified := splitQualified(text[i:end], len(opts.From))
+		newPath, ok := opts.move(pkg)
+		if ok && (i == 0 || !isPathChar(text[i-1])) {
+			b.WriteString(text[:i])
+			b.WriteString(newPath)
+			b.WriteString(qualified)
+		} else {
+			b.WriteString(text[:end])
+		}
+		text = text[end:]
+	}
+	b.WriteString(text)
+	return []byte(b.String()), nil
+}
+
+// splitQualified splits a qualified identifier, such as .Status in
+// example.com/old/db.Status, off the end of p, an import path at least
+// fromLen bytes long, so the package is moved without it.
+func splitQualified(p string, fromLen int) (pkg, qualified string) {
+	last := p[strings.LastIndexByte(p, '/')+1:]
+	dot := strings.IndexByte(last, '.')
+	if dot < 0 || !token.IsIdentifier(last[dot+1:]) || len(p)-len(last)+dot < fromLen {
+		return p, ""
+	}
+	return p[:len(p)-len(last)+dot], last[dot:]
+}
+
+// isPathChar reports whether c may appear in an import path.
+func isPathChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("-._~/", c) >= 0
+}
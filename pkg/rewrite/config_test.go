@@ -0,0 +1,69 @@
+package rewrite
+
+import "testing"
+
+func TestPaths(t *testing.T) {
+	moved := Options{
+		From: "example.com/old",
+		To:   "example.com/new",
+		MapPackage: func(dir string) string {
+			if dir == "pkg/db" {
+				return "internal/db"
+			}
+			return dir
+		},
+	}
+	tests := []struct {
+		opts     Options
+		in, want string
+	}{
+		{moved, "local-prefixes: example.com/old", "local-prefixes: example.com/new"},
+		{moved, "- prefix(example.com/old/pkg)", "- prefix(example.com/new/pkg)"},
+		{moved, "go_type: example.com/old/pkg/db.Status", "go_type: example.com/new/internal/db.Status"},
+		{moved, "-X example.com/old/internal/version.Version={{.Version}}", "-X example.com/new/internal/version.Version={{.Version}}"},
+		{moved, "go run example.com/old/cmd/tool.", "go run example.com/new/cmd/tool."},
+		{moved, "example.com/older and notexample.com/old", "example.com/older and notexample.com/old"},
+		{moved, "example.com/old example.com/old/", "example.com/new example.com/new/"},
+		{moved, "no module path here", "no module path here"},
+		{Options{To: "example.com/new"}, "local-prefixes: example.com/old", "local-prefixes: example.com/old"},
+	}
+	for _, tt := range tests {
+		got, err := Paths([]byte(tt.in), tt.opts)
+		if err != nil {
+			t.Errorf("Paths(%q): %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Paths(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	opts := Options{From: "example.com/old", To: "example.com/new"}
+	for name, rewritten := range map[string]bool{
+		".golangci.yml":                  true,
+		"deploy/Dockerfile":              true,
+		`build\Makefile`:                 true,
+		"config.yaml":                    false,
+		"docs/example.com.txt":           false,
+		".github/workflows/ci.yml":       true,
+		`.github\workflows\release.yaml`: true,
+		"workflows/ci.yml":               false,
+		".github/ci.yml":                 false,
+	} {
+		got, err := Config(name, []byte("example.com/old"), opts)
+		if err != nil {
+			t.Errorf("Config(%s): %v", name, err)
+			continue
+		}
+		if want := map[bool]string{true: "example.com/new", false: "example.com/old"}[rewritten]; string(got) != want {
+			t.Errorf("Config(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	Register("*.tmpl", Paths)
+	if _, ok := Lookup("templates/service.tmpl"); !ok {
+		t.Error("Lookup found no handler for a registered pattern")
+	}
+}
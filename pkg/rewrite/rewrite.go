@@ -0,0 +1,197 @@
+// Package rewrite moves Go sources from one module path to another. It
+// rewrites import paths, the module statement of go.mod and, optionally, the
+// name of the root package, editing the sources in place so that comments and
+// formatting are preserved. gonew uses it to turn a template module into a new
+// project and to rename projects, and it suits other tools that copy or split
+// modules:
+//
+//	data, err := rewrite.GoFile("cmd/main.go", data, rewrite.Options{
+//		From:              "example.com/old",
+//		To:                "example.com/new",
+//		RenameRootPackage: true,
+//		IncludeTests:      true,
+//	})
+package rewrite
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/betterde/gonew/pkg/edit"
+	"golang.org/x/mod/modfile"
+)
+
+// AliasPolicy decides how imports of the renamed root package are written.
+type AliasPolicy int
+
+const (
+	// AliasOriginal imports the root package under its original name when
+	// the last elements of the module paths differ, so that the importing
+	// code keeps compiling without touching its identifiers.
+	AliasOriginal AliasPolicy = iota
+	// AliasNever leaves imports of the root package unnamed.
+	AliasNever
+)
+
+// ImportCommentPolicy decides what happens to import comments, such as
+// package foo // import "example.com/old/foo", naming the package under From.
+type ImportCommentPolicy int
+
+const (
+	// ImportCommentRewrite points import comments at the package's new path.
+	ImportCommentRewrite ImportCommentPolicy = iota
+	// ImportCommentStrip removes import comments, which the go command
+	// ignores in module mode anyway.
+	ImportCommentStrip
+)
+
+// Options configure a rewrite.
+type Options struct {
+	// From and To are the old and new module paths.
+	From, To string
+	// RenameRootPackage renames the package of files in the module root,
+	// and its external test package, when named after the last element of
+	// From, to the last element of To.
+	RenameRootPackage bool
+	// Alias is the policy for imports of the root package.
+	Alias AliasPolicy
+	// ImportComments is the policy for import comments.
+	ImportComments ImportCommentPolicy
+	// IncludeTests rewrites _test.go files too, otherwise they are left as is.
+	IncludeTests bool
+	// MapPackage, if set, returns the new directory of the package at the
+	// slash-separated directory dir relative to the module root, for
+	// rewrites that also move packages. An empty result is the module root.
+	MapPackage func(dir string) string
+}
+
+// GoFile returns the Go source data of the file at name, a path relative to
+// the module root, rewritten from opts.From to opts.To.
+func GoFile(name string, data []byte, opts Options) ([]byte, error) {
+	if !opts.IncludeTests && strings.HasSuffix(name, "_test.go") {
+		return data, nil
+	}
+
+	fileSet := token.NewFileSet()
+	f, err := parser.ParseFile(fileSet, name, data, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := edit.NewBuffer(data)
+	at := func(p token.Pos) int {
+		return fileSet.File(p).Offset(p)
+	}
+
+	srcName := path.Base(opts.From)
+	dstName := path.Base(opts.To)
+	isRoot := !strings.Contains(filepath.ToSlash(name), "/")
+	if opts.RenameRootPackage && isRoot {
+		if pkg := f.Name.Name; pkg == srcName || pkg == srcName+"_test" {
+			target := dstName + strings.TrimPrefix(pkg, srcName)
+			if !token.IsIdentifier(target) {
+				return nil, fmt.Errorf("%s: cannot rename package %s to package %s: invalid package name", name, pkg, target)
+			}
+			buf.Replace(at(f.Name.Pos()), at(f.Name.End()), target)
+		}
+	}
+
+	if comment := importComment(fileSet, f); comment != nil {
+		quoted := importCommentPattern.FindStringSubmatch(comment.Text)[1]
+		pathStr, err := strconv.Unquote(quoted)
+		if newPath, ok := opts.move(pathStr); err == nil && ok {
+			start, end := at(comment.Pos()), at(comment.End())
+			if opts.ImportComments == ImportCommentStrip {
+				// Take the space before the comment along.
+				for start > 0 && (data[start-1] == ' ' || data[start-1] == '\t') {
+					start--
+				}
+				buf.Delete(start, end)
+			} else {
+				buf.Replace(start, end, strings.Replace(comment.Text, quoted, strconv.Quote(newPath), 1))
+			}
+		}
+	}
+
+	for _, spec := range f.Imports {
+		pathStr, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if pathStr == opts.From && opts.Alias == AliasOriginal && srcName != dstName && spec.Name == nil {
+			// Add package rename because source code uses original name.
+			// The renaming looks strange, but template authors are unlikely to
+			// create a template where the root package is imported by packages
+			// in subdirectories, and the renaming at least keeps the code working.
+			// A more sophisticated approach would be to rename the uses of
+			// the package identifier in the file too, but then you have to worry about
+			// name collisions, and given how unlikely this is, it doesn't seem worth
+			// trying to clean up the file that way.
+			buf.Insert(at(spec.Path.Pos()), srcName+" ")
+		}
+		if newPath, ok := opts.move(pathStr); ok {
+			buf.Replace(at(spec.Path.Pos()), at(spec.Path.End()), strconv.Quote(newPath))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// move returns the new import path of the package at pathStr, and whether
+// it is a package of From at all.
+func (opts *Options) move(pathStr string) (string, bool) {
+	if pathStr == opts.From {
+		return opts.To, true
+	}
+	if !strings.HasPrefix(pathStr, opts.From+"/") {
+		return "", false
+	}
+	// Change import path to begin with To, at the package's new location
+	pkg := strings.TrimPrefix(pathStr, opts.From+"/")
+	if opts.MapPackage != nil {
+		pkg = opts.MapPackage(pkg)
+	}
+	if pkg == "" {
+		return opts.To, true
+	}
+	return opts.To + "/" + pkg, true
+}
+
+// importCommentPattern matches the text of an import comment, capturing the
+// quoted import path.
+var importCommentPattern = regexp.MustCompile(`^(?://|/\*)\s*import\s+("[^"]*")\s*(?:\*/)?$`)
+
+// importComment returns the import comment of f, a comment following the
+// package clause on the same line, or nil if it has none.
+func importComment(fileSet *token.FileSet, f *ast.File) *ast.Comment {
+	line := fileSet.Position(f.Name.End()).Line
+	for _, group := range f.Comments {
+		for _, comment := range group.List {
+			if comment.Pos() < f.Name.End() || fileSet.Position(comment.Pos()).Line != line {
+				continue
+			}
+			if importCommentPattern.MatchString(comment.Text) {
+				return comment
+			}
+		}
+	}
+	return nil
+}
+
+// GoMod returns the go.mod content in data with its module path set to mod.
+func GoMod(data []byte, mod string) ([]byte, error) {
+	file, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.AddModuleStmt(mod); err != nil {
+		return nil, err
+	}
+	return file.Format()
+}
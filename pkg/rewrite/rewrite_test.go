@@ -0,0 +1,109 @@
+package rewrite
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestGoFile(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			"main.go",
+			"package main\n\nimport \"example.com/old/internal/app\"\n",
+			"package main\n\nimport \"example.com/new/internal/app\"\n",
+		},
+		{
+			"old.go",
+			"package old // import \"example.com/old\"\n",
+			"package new // import \"example.com/new\"\n",
+		},
+		{
+			"cmd/root.go",
+			"package cmd\n\nimport (\n\t\"fmt\"\n\t\"example.com/old\"\n)\n",
+			"package cmd\n\nimport (\n\t\"fmt\"\n\told \"example.com/new\"\n)\n",
+		},
+		{
+			"cmd/other.go",
+			"package cmd\n\nimport \"example.com/older\"\n",
+			"package cmd\n\nimport \"example.com/older\"\n",
+		},
+		{
+			"tags.go",
+			"//go:build ignore\n\npackage main\n",
+			"//go:build ignore\n\npackage main\n",
+		},
+	}
+	opts := Options{From: "example.com/old", To: "example.com/new", RenameRootPackage: true}
+	for _, tt := range tests {
+		got, err := GoFile(tt.name, []byte(tt.src), opts)
+		if err != nil {
+			t.Errorf("GoFile(%s): %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("GoFile(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGoFileSyntaxError(t *testing.T) {
+	opts := Options{From: "example.com/old", To: "example.com/new"}
+	for _, src := range []string{"", "package {{ .Name }}\n", "\xff\xfe", "package main\nimport \"example.com/old\n"} {
+		if _, err := GoFile("main.go", []byte(src), opts); err == nil {
+			t.Errorf("GoFile(%q) succeeded, want a syntax error", src)
+		}
+	}
+}
+
+func TestGoMod(t *testing.T) {
+	got, err := GoMod([]byte("module example.com/old\n\ngo 1.24\n"), "example.com/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "module example.com/new\n\ngo 1.24\n"; string(got) != want {
+		t.Errorf("GoMod = %q, want %q", got, want)
+	}
+	if _, err := GoMod([]byte("module {{ .Module }\n"), "example.com/new"); err == nil {
+		t.Errorf("GoMod of an invalid go.mod succeeded")
+	}
+}
+
+func FuzzGoFile(f *testing.F) {
+	for _, src := range []string{
+		"package old\n\nimport \"example.com/old/a\"\n",
+		"package old // import \"example.com/old\"\n",
+		"package old /* import \"example.com/old\" */\n\nimport (\n\tx \"example.com/old\"\n\t. \"example.com/old/b\"\n)\n",
+		"//go:build linux && !cgo\n\npackage old_test\n\nimport _ \"example.com/old\"\n",
+		"\ufeffpackage old\n",
+		"package old\r\n\r\nimport \"example.com/old\"\r\n",
+		"package old\nimport \"example.com/old/\\u0041\"\n",
+		"package old\nimport `example.com/old`\n",
+		"package old // import \"example.com/old\" // import \"example.com/old\"\n",
+		"package old\nfunc main() {\n",
+		"\xff\xfepackage old\n",
+		"//line other.go:10\npackage old // import \"example.com/old\"\n",
+	} {
+		f.Add("old.go", src, "example.com/old", "example.com/new")
+		f.Add("pkg/a.go", src, "example.com/old", "github.com/org/new-name")
+	}
+	f.Fuzz(func(t *testing.T, name, src, from, to string) {
+		opts := Options{From: from, To: to, RenameRootPackage: true, IncludeTests: true}
+		got, err := GoFile(name, []byte(src), opts)
+		if err != nil {
+			return
+		}
+		if module.CheckImportPath(from) != nil || module.CheckImportPath(to) != nil || strings.HasSuffix(name, "_test.go") {
+			return
+		}
+		// A source that parsed must still parse once rewritten.
+		if _, err := parser.ParseFile(token.NewFileSet(), name, got, parser.ImportsOnly|parser.ParseComments); err != nil {
+			t.Errorf("GoFile(%q) = %q, which does not parse: %v", src, got, err)
+		}
+	})
+}
@@ -0,0 +1,314 @@
+// Package validate checks variable answers against the rules declared by
+// templates. It has no dependency on prompting or the network, so the same
+// rules run in the CLI and in automation, and template authors can exercise
+// their rules in Go tests:
+//
+//	rule, err := validate.Compile(validate.Spec{Required: true, Named: []string{"go_package"}})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := rule.Validate("my-service"); err == nil {
+//		t.Error("accepted an invalid package name")
+//	}
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/text/language"
+)
+
+// A Rule checks a single answer.
+type Rule interface {
+	Validate(value string) error
+}
+
+// RuleFunc adapts a function to a Rule.
+type RuleFunc func(value string) error
+
+// Validate calls f(value).
+func (f RuleFunc) Validate(value string) error {
+	return f(value)
+}
+
+// All returns a rule that passes when every rule passes, reporting the first failure.
+func All(rules ...Rule) Rule {
+	return RuleFunc(func(value string) error {
+		for _, rule := range rules {
+			if err := rule.Validate(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Required rejects empty answers.
+func Required() Rule {
+	return RuleFunc(func(value string) error {
+		if value == "" {
+			return errors.New("a value is required")
+		}
+		return nil
+	})
+}
+
+// Regexp returns a rule accepting answers that match pattern.
+func Regexp(pattern string) (Rule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return RuleFunc(func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("%q does not match %s", value, pattern)
+		}
+		return nil
+	}), nil
+}
+
+// Identifier accepts Go identifiers.
+func Identifier() Rule {
+	return RuleFunc(func(value string) error {
+		if !token.IsIdentifier(value) {
+			return fmt.Errorf("%q is not a valid Go identifier", value)
+		}
+		return nil
+	})
+}
+
+// ModulePath accepts Go module paths.
+func ModulePath() Rule {
+	return RuleFunc(func(value string) error {
+		return module.CheckPath(value)
+	})
+}
+
+// GoPackage accepts Go package names: identifiers other than keywords and
+// the blank identifier.
+func GoPackage() Rule {
+	return RuleFunc(func(value string) error {
+		if !token.IsIdentifier(value) || value == "_" {
+			return fmt.Errorf("%q is not a valid Go package name", value)
+		}
+		return nil
+	})
+}
+
+// Semver accepts semantic versions such as 1.2.3 or v1.2.3-rc.1.
+func Semver() Rule {
+	return RuleFunc(func(value string) error {
+		v := value
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		if semver.Canonical(v) != v && semver.Canonical(v)+semver.Build(v) != v {
+			return fmt.Errorf("%q is not a semantic version such as 1.2.3", value)
+		}
+		return nil
+	})
+}
+
+// Hostname accepts DNS host names as RFC 1123 defines them, such as
+// api.example.com.
+func Hostname() Rule {
+	return RuleFunc(func(value string) error {
+		if len(value) > 253 {
+			return fmt.Errorf("%q is longer than 253 characters", value)
+		}
+		for label := range strings.SplitSeq(strings.TrimSuffix(value, "."), ".") {
+			if !hostLabel.MatchString(label) {
+				return fmt.Errorf("%q is not a valid host name", value)
+			}
+		}
+		return nil
+	})
+}
+
+// hostLabel matches a label of a host name.
+var hostLabel = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// OneOf accepts the answers listed in options.
+func OneOf(options ...string) Rule {
+	return RuleFunc(func(value string) error {
+		if !slices.Contains(options, value) {
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(options, ", "))
+		}
+		return nil
+	})
+}
+
+// Range accepts numbers between min and max, inclusive. A nil bound is no
+// bound.
+func Range(min, max *float64) Rule {
+	return RuleFunc(func(value string) error {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		if min != nil && n < *min {
+			return fmt.Errorf("%s is less than %v", value, *min)
+		}
+		if max != nil && n > *max {
+			return fmt.Errorf("%s is greater than %v", value, *max)
+		}
+		return nil
+	})
+}
+
+// Type returns a rule accepting answers that parse as the named type:
+// string, int, float, bool, list, port, a TCP port number, locale, a BCP-47
+// language tag such as pt-BR, or select, one of a list of options checked
+// with OneOf. Any answer is a valid list.
+func Type(name string) (Rule, error) {
+	switch name {
+	case "", "string", "list", "select":
+		return RuleFunc(func(string) error { return nil }), nil
+	case "int":
+		return RuleFunc(func(value string) error {
+			if _, err := strconv.Atoi(value); err != nil {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+			return nil
+		}), nil
+	case "float":
+		return RuleFunc(func(value string) error {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+			return nil
+		}), nil
+	case "bool":
+		return RuleFunc(func(value string) error {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("%q is not a boolean", value)
+			}
+			return nil
+		}), nil
+	case "port":
+		return RuleFunc(func(value string) error {
+			if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+				return fmt.Errorf("%q is not a port number", value)
+			}
+			return nil
+		}), nil
+	case "locale":
+		return RuleFunc(func(value string) error {
+			if _, err := language.Parse(value); err != nil {
+				return fmt.Errorf("%q is not a BCP-47 language tag", value)
+			}
+			return nil
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", name)
+}
+
+var (
+	mu    sync.RWMutex
+	named = map[string]Rule{
+		"go_package":  GoPackage(),
+		"hostname":    Hostname(),
+		"identifier":  Identifier(),
+		"module_path": ModulePath(),
+		"semver":      Semver(),
+	}
+)
+
+// Register makes rule available to Named and Spec.Named under name,
+// replacing any rule registered before under the same name.
+func Register(name string, rule Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	named[name] = rule
+}
+
+// Named returns the rule registered under name.
+func Named(name string) (Rule, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	rule, ok := named[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown validator %q, available validators: %v", name, names())
+	}
+	return rule, nil
+}
+
+func names() []string {
+	list := make([]string, 0, len(named))
+	for name := range named {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Spec declares the rules for one variable.
+type Spec struct {
+	// Required rejects empty answers; optional empty answers skip the other rules.
+	Required bool
+	// Type is the type the answer must parse as, see Type.
+	Type string
+	// Pattern is a regular expression the answer must match.
+	Pattern string
+	// Named lists registered rules the answer must pass.
+	Named []string
+	// Options, if any, are the only answers accepted.
+	Options []string
+	// Min and Max, if set, bound numeric answers.
+	Min, Max *float64
+}
+
+// Compile turns spec into a single rule.
+func Compile(spec Spec) (Rule, error) {
+	var rules []Rule
+
+	typ, err := Type(spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, typ)
+
+	if len(spec.Options) > 0 {
+		rules = append(rules, OneOf(spec.Options...))
+	}
+	if spec.Min != nil || spec.Max != nil {
+		rules = append(rules, Range(spec.Min, spec.Max))
+	}
+
+	if spec.Pattern != "" {
+		rule, err := Regexp(spec.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	for _, name := range spec.Named {
+		rule, err := Named(name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	rest := All(rules...)
+	return RuleFunc(func(value string) error {
+		if value == "" {
+			if spec.Required {
+				return Required().Validate(value)
+			}
+			return nil
+		}
+		return rest.Validate(value)
+	}), nil
+}
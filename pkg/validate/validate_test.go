@@ -0,0 +1,193 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// check runs rule on every answer of accept and reject.
+func check(t *testing.T, name string, rule Rule, accept, reject []string) {
+	t.Helper()
+	for _, value := range accept {
+		if err := rule.Validate(value); err != nil {
+			t.Errorf("%s rejected %q: %v", name, value, err)
+		}
+	}
+	for _, value := range reject {
+		if err := rule.Validate(value); err == nil {
+			t.Errorf("%s accepted %q", name, value)
+		}
+	}
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           Rule
+		accept, reject []string
+	}{
+		{
+			"Required", Required(),
+			[]string{"x", " "},
+			[]string{""},
+		},
+		{
+			"Identifier", Identifier(),
+			[]string{"x", "_", "myService", "ñame", "x1"},
+			[]string{"", "1x", "my-service", "func", "a.b"},
+		},
+		{
+			"GoPackage", GoPackage(),
+			[]string{"main", "service", "v2"},
+			[]string{"", "_", "my-service", "package", "2fa"},
+		},
+		{
+			"ModulePath", ModulePath(),
+			[]string{"example.com/service", "github.com/org/repo/v2"},
+			[]string{"", "service", "example.com/Service/../x", "https://example.com/x", "example.com/a b"},
+		},
+		{
+			"Semver", Semver(),
+			[]string{"1.2.3", "v1.2.3", "v1.2.3-rc.1", "1.0.0+build.5"},
+			[]string{"", "1.2", "v1", "1.2.3.4", "latest", "01.2.3"},
+		},
+		{
+			"Hostname", Hostname(),
+			[]string{"localhost", "api.example.com", "api.example.com.", "a-b.c0", "XN--80AK6AA92E.com"},
+			[]string{"", "-api.example.com", "api-.example.com", "api..example.com", "api_example.com", strings.Repeat("a", 64) + ".com", strings.Repeat("a.", 127) + "aa"},
+		},
+		{
+			"OneOf", OneOf("postgres", "mysql"),
+			[]string{"postgres", "mysql"},
+			[]string{"", "sqlite", "Postgres"},
+		},
+		{
+			"Range", Range(ptr(1024), ptr(65535)),
+			[]string{"1024", "8080", "65535", "2e3"},
+			[]string{"", "80", "65536", "port"},
+		},
+		{
+			"Range without bounds", Range(nil, nil),
+			[]string{"-1e9", "0", "3.14"},
+			[]string{"", "x"},
+		},
+	}
+	for _, tt := range tests {
+		check(t, tt.name, tt.rule, tt.accept, tt.reject)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	rule, err := Regexp("^[a-z][a-z0-9-]*$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "Regexp", rule, []string{"service", "my-service2"}, []string{"", "2service", "My-service", "a_b"})
+
+	if _, err := Regexp("[a-z"); err == nil {
+		t.Error("Regexp accepted an invalid pattern")
+	}
+}
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept, reject []string
+	}{
+		{"", []string{"", "anything"}, nil},
+		{"string", []string{"", "anything"}, nil},
+		{"list", []string{"a,b", `["a"]`}, nil},
+		{"int", []string{"0", "-3", "42"}, []string{"", "1.5", "x", "1e3"}},
+		{"float", []string{"0", "1.5", "-2e3"}, []string{"", "x", "1,5"}},
+		{"bool", []string{"true", "false", "1", "0", "TRUE"}, []string{"", "yes", "on"}},
+		{"port", []string{"1", "8080", "65535"}, []string{"", "0", "65536", "-1", "http"}},
+		{"locale", []string{"en", "pt-BR", "zh-Hant-TW"}, []string{"", "not a tag", "en_US_"}},
+	}
+	for _, tt := range tests {
+		rule, err := Type(tt.name)
+		if err != nil {
+			t.Errorf("Type(%q): %v", tt.name, err)
+			continue
+		}
+		check(t, "Type "+tt.name, rule, tt.accept, tt.reject)
+	}
+	if _, err := Type("uuid"); err == nil {
+		t.Error("Type accepted an unknown type")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	for _, name := range []string{"go_package", "hostname", "identifier", "module_path", "semver"} {
+		if _, err := Named(name); err != nil {
+			t.Errorf("Named(%q): %v", name, err)
+		}
+	}
+	if _, err := Named("uuid"); err == nil || !strings.Contains(err.Error(), "semver") {
+		t.Errorf("Named of an unknown validator = %v, want an error listing the validators", err)
+	}
+
+	Register("even", RuleFunc(func(value string) error {
+		if len(value)%2 != 0 {
+			return errors.New("odd length")
+		}
+		return nil
+	}))
+	rule, err := Named("even")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, "registered", rule, []string{"", "ab"}, []string{"a"})
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           Spec
+		accept, reject []string
+	}{
+		{
+			"optional", Spec{Named: []string{"semver"}},
+			[]string{"", "1.2.3"},
+			[]string{"1.2"},
+		},
+		{
+			"required", Spec{Required: true, Named: []string{"go_package"}},
+			[]string{"service"},
+			[]string{"", "my-service"},
+		},
+		{
+			"bounded int", Spec{Type: "int", Min: ptr(1), Max: ptr(10)},
+			[]string{"1", "10"},
+			[]string{"0", "11", "5.5"},
+		},
+		{
+			"select", Spec{Required: true, Type: "select", Options: []string{"a", "b"}},
+			[]string{"a", "b"},
+			[]string{"", "c"},
+		},
+		{
+			"pattern and validator", Spec{Pattern: "^v", Named: []string{"semver"}},
+			[]string{"v1.0.0"},
+			[]string{"1.0.0", "vx"},
+		},
+	}
+	for _, tt := range tests {
+		rule, err := Compile(tt.spec)
+		if err != nil {
+			t.Errorf("Compile %s: %v", tt.name, err)
+			continue
+		}
+		check(t, tt.name, rule, tt.accept, tt.reject)
+	}
+
+	for _, spec := range []Spec{{Type: "uuid"}, {Pattern: "("}, {Named: []string{"uuid"}}} {
+		if _, err := Compile(spec); err == nil {
+			t.Errorf("Compile(%+v) succeeded", spec)
+		}
+	}
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}